@@ -0,0 +1,111 @@
+package sessions
+
+//===========[STRUCTS]====================================================================================================
+
+//AttrKey is a typed name of a session attribute. Declare one per attribute, e.g.
+//var CSRFToken = sessions.NewAttrKey[string]("csrf"), and use it to read and write the attribute without type
+//assertions
+type AttrKey[T any] struct {
+	name string
+}
+
+//Name returns the name the attribute is stored under
+func (k AttrKey[T]) Name() string {
+	return k.name
+}
+
+//Get returns the attribute of the session and whether it's set. Attributes of a different type are reported as not
+//set
+func (k AttrKey[T]) Get(s Attributer) (T, bool) {
+	v, exist := s.GetAttr(k.name)
+	t, ok := v.(T)
+	return t, exist && ok
+}
+
+//Set stores the attribute in the session
+func (k AttrKey[T]) Set(s Attributer, v T) {
+	s.SetAttr(k.name, v)
+}
+
+//Delete removes the attribute from the session
+func (k AttrKey[T]) Delete(s Attributer) {
+	s.DeleteAttr(k.name)
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//NewAttrKey creates a typed key for the attribute with the name supplied
+func NewAttrKey[T any](name string) AttrKey[T] {
+	return AttrKey[T]{name: name}
+}
+
+//SetAttr stores an attribute of the session. Attributes are kept apart from Value and are meant for infrastructure
+//concerns, such as CSRF tokens or authentication level, so libraries don't need to change the value type. Prefer
+//AttrKey for type safety. While the store is read-only, the attribute is left as it was and ErrReadOnly goes to
+//Requirements.OnError, the same goes for UpdateAttr and DeleteAttr
+func (s *Session[TValue]) SetAttr(name string, v any) {
+	if err := s.writable(); err != nil {
+		s.store.reportError(err)
+		return
+	}
+
+	s.mx.Lock()
+	if s.session.attrs == nil {
+		s.session.attrs = make(map[string]any)
+	}
+	s.session.attrs[name] = v
+	s.touch()
+	s.mx.Unlock()
+}
+
+//GetAttr returns the attribute stored under the name and whether it exists
+func (s *Session[TValue]) GetAttr(name string) (any, bool) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	v, exist := s.session.attrs[name]
+	return v, exist
+}
+
+//UpdateAttr replaces the attribute with the result of the function supplied while holding the session lock, so
+//read-modify-write sequences can't race with each other. The function must not call other methods of the session
+func (s *Session[TValue]) UpdateAttr(name string, f func(v any, exist bool) any) {
+	if err := s.writable(); err != nil {
+		s.store.reportError(err)
+		return
+	}
+
+	s.mx.Lock()
+	if s.session.attrs == nil {
+		s.session.attrs = make(map[string]any)
+	}
+	v, exist := s.session.attrs[name]
+	s.session.attrs[name] = f(v, exist)
+	s.touch()
+	s.mx.Unlock()
+}
+
+//DeleteAttr removes the attribute stored under the name
+func (s *Session[TValue]) DeleteAttr(name string) {
+	if err := s.writable(); err != nil {
+		s.store.reportError(err)
+		return
+	}
+
+	s.mx.Lock()
+	delete(s.session.attrs, name)
+	s.touch()
+	s.mx.Unlock()
+}
+
+//Attrs returns a copy of every attribute of the session, e.g. for a Backend to store them
+func (s *Session[TValue]) Attrs() map[string]any {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	attrs := make(map[string]any, len(s.session.attrs))
+	for k, v := range s.session.attrs {
+		attrs[k] = v
+	}
+
+	return attrs
+}
@@ -0,0 +1,69 @@
+package sessions
+
+import (
+	"golang.org/x/text/language"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestAttrKey(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	s := ss.New("value").(*Session[string])
+
+	csrf := NewAttrKey[string]("csrf")
+	level := NewAttrKey[int]("csrf")
+
+	csrf.Set(s, "token")
+
+	if v, ok := csrf.Get(s); !ok || v != "token" {
+		t.Errorf("Expected attribute \"token\", got \"%s\"", v)
+	}
+
+	if _, ok := level.Get(s); ok {
+		t.Errorf("Expected attribute of a different type to be reported as not set")
+	}
+
+	if s.Value() != "value" || len(s.Attrs()) != 1 {
+		t.Errorf("Expected attributes to be kept apart from the value")
+	}
+
+	csrf.Delete(s)
+	if _, ok := csrf.Get(s); ok {
+		t.Errorf("Expected attribute to be deleted")
+	}
+}
+
+func TestSession_SetAttr_Touch(t *testing.T) {
+	var reported []error
+	ss := initializeSessionStore(0, &Requirements[string]{OnError: func(err error) { reported = append(reported, err) }})
+	s := ss.New("value").(*Session[string])
+
+	if err := ss.Flush(func(ISession[string], []string) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	s.SetAttr("level", 2)
+
+	flushed := 0
+	if err := ss.Flush(func(ISession[string], []string) error { flushed++; return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if flushed != 1 {
+		t.Errorf("Expected setting an attribute to mark the session as modified")
+	}
+
+	ss.SetReadOnly(true)
+	s.SetAttr("level", 3)
+	s.DeleteAttr("level")
+	s.SetLocale(language.German)
+
+	if v, _ := s.GetAttr("level"); v != 2 || s.Locale() != language.Und {
+		t.Errorf("Expected attributes not to change while the store is read-only, got %v", v)
+	}
+
+	if len(reported) != 3 {
+		t.Errorf("Expected refused changes to be reported, got %v", reported)
+	}
+}
@@ -0,0 +1,139 @@
+package authsessions
+
+import (
+	"github.com/emillis/sessions"
+	"net/http"
+)
+
+//===========[STRUCTURES]===============================================================================================
+
+//Store keeps the principal of the logged in user, e.g. a user ID or a struct describing the user, as the value of
+//the session. Sessions exist only for logged in users
+type Store[TPrincipal any] struct {
+	*sessions.SessionStore[TPrincipal]
+
+	//Audit receives security relevant events, such as start and end of impersonation
+	Audit func(ev AuditEvent[TPrincipal])
+}
+
+//Login starts a session for the principal and sets its cookie on w. If the request already carries a session, it's
+//reused under a new UID, so a UID planted before login can't be used to hijack the session, and any impersonation
+//going on in it ends. If the UID can't be replaced, the login fails with the error and the session is left as it was
+func (s *Store[TPrincipal]) Login(w http.ResponseWriter, r *http.Request, principal TPrincipal) error {
+	store, err := s.TenantForE(r)
+	if err != nil {
+		return err
+	}
+
+	session := s.current(r)
+
+	if session == nil {
+		created, err := store.NewE(principal)
+		if err != nil {
+			return err
+		}
+		session = created
+	} else {
+		if err := regenerate(session); err != nil {
+			return err
+		}
+
+		if err := setValue(session, principal); err != nil {
+			return err
+		}
+
+		if attrs, ok := session.(sessions.Attributer); ok {
+			attrs.DeleteAttr(originalAttr)
+		}
+	}
+
+	if c, ok := session.(sessions.CookieWriter); ok {
+		c.SetHttpCookie(w, nil)
+	}
+
+	return nil
+}
+
+//Logout ends the session of the request, if there is one, and deletes its cookie. With Requirements.TenantOf, the
+//session is removed from the partition of the tenant
+func (s *Store[TPrincipal]) Logout(w http.ResponseWriter, r *http.Request) {
+	if session := s.current(r); session != nil {
+		if store := s.TenantFor(r); store != nil {
+			store.Remove(session.Uid())
+		}
+	}
+
+	s.ExpireHttpCookie(w)
+}
+
+//CurrentUser returns the principal of the user the request belongs to, and false if nobody is logged in
+func (s *Store[TPrincipal]) CurrentUser(r *http.Request) (TPrincipal, bool) {
+	session := s.current(r)
+	if session == nil {
+		var zero TPrincipal
+		return zero, false
+	}
+
+	return session.Value(), true
+}
+
+//RequireUser lets only requests of logged in users through. Everyone else gets 401. The session is available to next
+//through sessions.FromContext
+func (s *Store[TPrincipal]) RequireUser(next http.Handler) http.Handler {
+	return s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sessions.FromContext[TPrincipal](r.Context()) == nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}))
+}
+
+//Returns the session of the request, preferring the one Middleware has already loaded. Otherwise it's looked up in
+//the partition of the tenant the request belongs to
+func (s *Store[TPrincipal]) current(r *http.Request) sessions.ISession[TPrincipal] {
+	if r == nil {
+		return nil
+	}
+
+	if session := sessions.FromContext[TPrincipal](r.Context()); session != nil {
+		return session
+	}
+
+	store := s.TenantFor(r)
+	if store == nil {
+		return nil
+	}
+
+	return store.GetFromCookie(r)
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Gives the session a new UID, if it supports that
+func regenerate[TPrincipal any](session sessions.ISession[TPrincipal]) error {
+	if rg, ok := session.(sessions.Regenerator); ok {
+		if _, err := rg.RegenerateE(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Assigns the value, returning the validation error if the session is able to report it
+func setValue[TPrincipal any](session sessions.ISession[TPrincipal], v TPrincipal) error {
+	if u, ok := session.(sessions.Updater[TPrincipal]); ok {
+		return u.SetValueE(v)
+	}
+
+	session.SetValue(v)
+
+	return nil
+}
+
+//New wraps the SessionStore supplied into the auth layer
+func New[TPrincipal any](ss *sessions.SessionStore[TPrincipal]) *Store[TPrincipal] {
+	return &Store[TPrincipal]{SessionStore: ss}
+}
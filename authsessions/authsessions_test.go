@@ -0,0 +1,201 @@
+package authsessions
+
+import (
+	"github.com/emillis/sessions"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+//Returns a request carrying the cookies set on the response
+func requestWithCookies(w *httptest.ResponseRecorder) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	return r
+}
+
+func TestStore_Login(t *testing.T) {
+	store := New(sessions.New[string](nil))
+
+	w := httptest.NewRecorder()
+	if err := store.Login(w, httptest.NewRequest(http.MethodGet, "/", nil), "alice"); err != nil {
+		t.Fatalf("Expected login to succeed, got %s", err)
+	}
+
+	r := requestWithCookies(w)
+	if user, ok := store.CurrentUser(r); !ok || user != "alice" {
+		t.Errorf("Expected current user \"alice\", got \"%s\"", user)
+	}
+
+	protected := store.RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	protected.ServeHTTP(rec, r)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected logged in user to pass, got %d", rec.Code)
+	}
+
+	out := httptest.NewRecorder()
+	store.Logout(out, r)
+
+	if _, ok := store.CurrentUser(r); ok {
+		t.Errorf("Expected nobody to be logged in after logout")
+	}
+
+	if c := out.Result().Cookies(); len(c) != 1 || c[0].MaxAge >= 0 {
+		t.Errorf("Expected the cookie to be deleted on logout, got %v", c)
+	}
+
+	rec = httptest.NewRecorder()
+	protected.ServeHTTP(rec, r)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 after logout, got %d", rec.Code)
+	}
+}
+
+func TestStore_LogoutTenant(t *testing.T) {
+	store := New(sessions.New[string](&sessions.Requirements[string]{
+		TenantOf: func(r *http.Request) string { return "acme" },
+	}))
+
+	w := httptest.NewRecorder()
+	if err := store.Login(w, httptest.NewRequest(http.MethodGet, "/", nil), "alice"); err != nil {
+		t.Fatalf("Expected login to succeed, got %s", err)
+	}
+
+	r := requestWithCookies(w)
+	if user, ok := store.CurrentUser(r); !ok || user != "alice" {
+		t.Fatalf("Expected the session to be found in the partition of the tenant, got \"%s\"", user)
+	}
+
+	store.Logout(httptest.NewRecorder(), r)
+
+	if _, ok := store.CurrentUser(r); ok {
+		t.Errorf("Expected the session of the tenant to be ended on logout")
+	}
+}
+
+func TestStore_LoginRegenerates(t *testing.T) {
+	store := New(sessions.New[string](nil))
+	planted := store.New("anonymous")
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: planted.Key(), Value: planted.Uid()})
+	uid := planted.Uid()
+
+	if err := store.Login(httptest.NewRecorder(), r, "alice"); err != nil {
+		t.Fatalf("Expected login to succeed, got %s", err)
+	}
+
+	if store.Get(uid) != nil {
+		t.Errorf("Expected the UID known before login to stop working")
+	}
+}
+
+func TestStore_LoginFailsWithoutRegenerating(t *testing.T) {
+	ss := sessions.New[string](nil)
+	store := New(ss)
+	planted := store.New("anonymous")
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: planted.Key(), Value: planted.Uid()})
+
+	ss.SetReadOnly(true)
+
+	if err := store.Login(httptest.NewRecorder(), r, "alice"); err != sessions.ErrReadOnly {
+		t.Errorf("Expected login to fail with ErrReadOnly, got %v", err)
+	}
+
+	if planted.Value() != "anonymous" {
+		t.Errorf("Expected the session to be left as it was, got \"%s\"", planted.Value())
+	}
+}
+
+func TestStore_Impersonate(t *testing.T) {
+	store := New(sessions.New[string](nil))
+
+	var events []AuditEvent[string]
+	store.Audit = func(ev AuditEvent[string]) { events = append(events, ev) }
+
+	w := httptest.NewRecorder()
+	_ = store.Login(w, httptest.NewRequest(http.MethodGet, "/", nil), "admin")
+
+	w2 := httptest.NewRecorder()
+	if err := store.Impersonate(w2, requestWithCookies(w), "bob"); err != nil {
+		t.Fatalf("Expected impersonation to start, got %s", err)
+	}
+
+	r := requestWithCookies(w2)
+	if user, _ := store.CurrentUser(r); user != "bob" {
+		t.Errorf("Expected to act as \"bob\", got \"%s\"", user)
+	}
+	if original, ok := store.OriginalUser(r); !ok || original != "admin" {
+		t.Errorf("Expected original user \"admin\", got \"%s\"", original)
+	}
+
+	if attrs := store.ToRecord(store.current(r)).Metadata.Attrs; attrs[originalAttr] != "admin" {
+		t.Errorf("Expected the original user to be kept in the attributes of the session, got %v", attrs)
+	}
+
+	if err := store.Impersonate(httptest.NewRecorder(), r, "carol"); err != ErrImpersonating {
+		t.Errorf("Expected nested impersonation to be rejected, got %v", err)
+	}
+
+	w3 := httptest.NewRecorder()
+	if err := store.EndImpersonation(w3, r); err != nil {
+		t.Fatalf("Expected impersonation to end, got %s", err)
+	}
+
+	r = requestWithCookies(w3)
+	if user, _ := store.CurrentUser(r); user != "admin" {
+		t.Errorf("Expected to act as \"admin\" again, got \"%s\"", user)
+	}
+
+	if len(events) != 2 || events[0].Action != AuditImpersonationStarted || events[1].Actor != "admin" || events[1].Target != "bob" {
+		t.Errorf("Expected start and end of impersonation to be audited, got %v", events)
+	}
+}
+
+func TestStore_LoginEndsImpersonation(t *testing.T) {
+	store := New(sessions.New[string](nil))
+
+	w := httptest.NewRecorder()
+	_ = store.Login(w, httptest.NewRequest(http.MethodGet, "/", nil), "admin")
+
+	w2 := httptest.NewRecorder()
+	if err := store.Impersonate(w2, requestWithCookies(w), "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	w3 := httptest.NewRecorder()
+	if err := store.Login(w3, requestWithCookies(w2), "carol"); err != nil {
+		t.Fatal(err)
+	}
+
+	if original, ok := store.OriginalUser(requestWithCookies(w3)); ok {
+		t.Errorf("Expected login to end the impersonation, still acting for \"%s\"", original)
+	}
+}
+
+func TestStore_ImpersonateFails(t *testing.T) {
+	ss := sessions.New[string](nil)
+	store := New(ss)
+
+	w := httptest.NewRecorder()
+	_ = store.Login(w, httptest.NewRequest(http.MethodGet, "/", nil), "admin")
+	r := requestWithCookies(w)
+
+	ss.SetReadOnly(true)
+	if err := store.Impersonate(httptest.NewRecorder(), r, "bob"); err == nil {
+		t.Fatalf("Expected impersonation to fail while the store is read-only")
+	}
+	ss.SetReadOnly(false)
+
+	if original, ok := store.OriginalUser(r); ok {
+		t.Errorf("Expected no original user to be left behind, got \"%s\"", original)
+	}
+}
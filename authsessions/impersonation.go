@@ -0,0 +1,159 @@
+package authsessions
+
+import (
+	"errors"
+	"github.com/emillis/sessions"
+	"net/http"
+	"time"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//Attribute of the session the original identity is kept in during impersonation. It's not part of the session value,
+//so SetValue can't overwrite it, but it goes along with the session wherever its attributes do, e.g. into its record
+const originalAttr = "authsessions.original"
+
+var (
+	//ErrNotLoggedIn is returned when the request doesn't belong to a logged in user
+	ErrNotLoggedIn = errors.New("authsessions: not logged in")
+
+	//ErrImpersonating is returned by Impersonate when the session is already impersonating someone
+	ErrImpersonating = errors.New("authsessions: already impersonating")
+
+	//ErrNotImpersonating is returned by EndImpersonation when the session isn't impersonating anyone
+	ErrNotImpersonating = errors.New("authsessions: not impersonating")
+
+	//ErrUnsupportedSession is returned when the session doesn't implement sessions.Attributer
+	ErrUnsupportedSession = errors.New("authsessions: session doesn't support attributes")
+)
+
+//AuditAction names the event reported to Store.Audit
+type AuditAction string
+
+const (
+	//AuditImpersonationStarted is reported by Impersonate
+	AuditImpersonationStarted AuditAction = "impersonation_started"
+
+	//AuditImpersonationEnded is reported by EndImpersonation
+	AuditImpersonationEnded AuditAction = "impersonation_ended"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//AuditEvent describes a security relevant change of the acting principal
+type AuditEvent[TPrincipal any] struct {
+	Action AuditAction `json:"action" bson:"action"`
+
+	//Actor is the principal who is really using the session, e.g. the support agent
+	Actor TPrincipal `json:"actor" bson:"actor"`
+
+	//Target is the principal being impersonated
+	Target TPrincipal `json:"target" bson:"target"`
+
+	Time time.Time `json:"time" bson:"time"`
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Impersonate makes the logged in user act as the target, e.g. for support tooling. The original identity is kept
+//in an attribute of the session, outside its value, and restored by EndImpersonation. It's kept before the principal
+//is switched, so the session never acts as the target without it. The session gets a new UID and its cookie is set
+//on w again
+func (s *Store[TPrincipal]) Impersonate(w http.ResponseWriter, r *http.Request, target TPrincipal) error {
+	session, attrs, err := s.impersonationAttrs(r)
+	if err != nil {
+		return err
+	}
+
+	original := sessions.NewAttrKey[TPrincipal](originalAttr)
+	if _, exist := original.Get(attrs); exist {
+		return ErrImpersonating
+	}
+
+	actor := session.Value()
+	original.Set(attrs, actor)
+
+	if err := s.switchPrincipal(w, session, target); err != nil {
+		original.Delete(attrs)
+		return err
+	}
+
+	s.audit(AuditImpersonationStarted, actor, target)
+
+	return nil
+}
+
+//EndImpersonation restores the original identity of the session
+func (s *Store[TPrincipal]) EndImpersonation(w http.ResponseWriter, r *http.Request) error {
+	session, attrs, err := s.impersonationAttrs(r)
+	if err != nil {
+		return err
+	}
+
+	original := sessions.NewAttrKey[TPrincipal](originalAttr)
+	actor, exist := original.Get(attrs)
+	if !exist {
+		return ErrNotImpersonating
+	}
+
+	target := session.Value()
+	if err := s.switchPrincipal(w, session, actor); err != nil {
+		return err
+	}
+	original.Delete(attrs)
+
+	s.audit(AuditImpersonationEnded, actor, target)
+
+	return nil
+}
+
+//OriginalUser returns the principal who is really using the session while impersonating someone, and false if the
+//session isn't impersonating anyone
+func (s *Store[TPrincipal]) OriginalUser(r *http.Request) (TPrincipal, bool) {
+	_, attrs, err := s.impersonationAttrs(r)
+	if err != nil {
+		var zero TPrincipal
+		return zero, false
+	}
+
+	return sessions.NewAttrKey[TPrincipal](originalAttr).Get(attrs)
+}
+
+//Returns the session of the request together with the attributes the original identity is kept in
+func (s *Store[TPrincipal]) impersonationAttrs(r *http.Request) (sessions.ISession[TPrincipal], sessions.Attributer, error) {
+	session := s.current(r)
+	if session == nil {
+		return nil, nil, ErrNotLoggedIn
+	}
+
+	attrs, ok := session.(sessions.Attributer)
+	if !ok {
+		return nil, nil, ErrUnsupportedSession
+	}
+
+	return session, attrs, nil
+}
+
+//Makes the principal the acting one, under a new UID. Nothing is changed if the UID can't be replaced
+func (s *Store[TPrincipal]) switchPrincipal(w http.ResponseWriter, session sessions.ISession[TPrincipal], principal TPrincipal) error {
+	if err := regenerate(session); err != nil {
+		return err
+	}
+
+	if err := setValue(session, principal); err != nil {
+		return err
+	}
+
+	if c, ok := session.(sessions.CookieWriter); ok && w != nil {
+		c.SetHttpCookie(w, nil)
+	}
+
+	return nil
+}
+
+//Passes the event to Store.Audit, if set, timed by the clock of the store
+func (s *Store[TPrincipal]) audit(action AuditAction, actor, target TPrincipal) {
+	if s.Audit != nil {
+		s.Audit(AuditEvent[TPrincipal]{Action: action, Actor: actor, Target: target, Time: s.Now()})
+	}
+}
@@ -0,0 +1,275 @@
+package sessions
+
+import (
+	"errors"
+	"time"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//How many times saving a session is retried after resolving a version conflict
+const maxConflictRetries = 3
+
+//===========[INTERFACES]====================================================================================================
+
+//Backend is a persistent storage of sessions that can be shared between several nodes
+type Backend[TValue any] interface {
+	//Load returns the value and the version stored under the key. ErrNotFound is returned if the key is not stored.
+	//Keys are session storage keys, see Session.StorageKey
+	Load(key string) (value TValue, version uint64, err error)
+
+	//Save stores the session under StorageKeyOf(s) only if the stored version still equals expectedVersion (0 meaning
+	//it must not be stored yet) and returns the new version. If versions do not match, ErrVersionConflict must be
+	//returned. dirtyFields lists changed fields of the value, empty meaning the whole value has to be written
+	Save(s ISession[TValue], dirtyFields []string, expectedVersion uint64) (version uint64, err error)
+
+	//Remove deletes the session stored under the key
+	Remove(key string) error
+}
+
+//ExpiryListener is implemented by backends that keep state about sessions, e.g. ChainBackend, so they can drop it once
+//a session expires in the store. Expired sessions aren't removed from the Backend, which expires them on its own
+type ExpiryListener interface {
+	//SessionExpired is called with the storage key of the session that has expired
+	SessionExpired(key string)
+}
+
+//===========[STRUCTS]====================================================================================================
+
+//Session handed by a Backend to the one it wraps when it stores the session under another key or with another value.
+//Everything else comes from the original session, so the capabilities backends read it through, see Describer,
+//Expirer, Attributer and Tokener, still describe the original
+type storedSession[TStored, TValue any] struct {
+	original ISession[TValue]
+	key      string
+	value    TStored
+}
+
+//ISession
+
+func (s *storedSession[TStored, TValue]) Uid() string             { return s.original.Uid() }
+func (s *storedSession[TStored, TValue]) SetUid(uid string)       { s.original.SetUid(uid) }
+func (s *storedSession[TStored, TValue]) Value() TStored          { return s.value }
+func (s *storedSession[TStored, TValue]) SetValue(v TStored)      { s.value = v }
+func (s *storedSession[TStored, TValue]) Key() string             { return s.original.Key() }
+func (s *storedSession[TStored, TValue]) SetKey(k string)         { s.original.SetKey(k) }
+func (s *storedSession[TStored, TValue]) LastModified() time.Time { return s.original.LastModified() }
+func (s *storedSession[TStored, TValue]) UpdateLastModified()     { s.original.UpdateLastModified() }
+
+//Describer
+
+func (s *storedSession[TStored, TValue]) StorageKey() string { return s.key }
+
+func (s *storedSession[TStored, TValue]) CreatedAt() time.Time {
+	if d, ok := s.original.(Describer); ok {
+		return d.CreatedAt()
+	}
+	return time.Time{}
+}
+
+func (s *storedSession[TStored, TValue]) Version() uint64 {
+	if d, ok := s.original.(Describer); ok {
+		return d.Version()
+	}
+	return 0
+}
+
+func (s *storedSession[TStored, TValue]) RoutingKey() string {
+	if d, ok := s.original.(Describer); ok {
+		return d.RoutingKey()
+	}
+	return s.original.Uid()
+}
+
+func (s *storedSession[TStored, TValue]) Label() string {
+	if d, ok := s.original.(Describer); ok {
+		return d.Label()
+	}
+	return ""
+}
+
+func (s *storedSession[TStored, TValue]) Priority() Priority {
+	if d, ok := s.original.(Describer); ok {
+		return d.Priority()
+	}
+	return PriorityNormal
+}
+
+//Expirer
+
+func (s *storedSession[TStored, TValue]) ExpiresAt() time.Time {
+	if e, ok := s.original.(Expirer); ok {
+		return e.ExpiresAt()
+	}
+	return time.Time{}
+}
+
+func (s *storedSession[TStored, TValue]) ExpireAt(t time.Time) {
+	if e, ok := s.original.(Expirer); ok {
+		e.ExpireAt(t)
+	}
+}
+
+func (s *storedSession[TStored, TValue]) SuspendExpiry() func() {
+	if e, ok := s.original.(Expirer); ok {
+		return e.SuspendExpiry()
+	}
+	return func() {}
+}
+
+//Attributer
+
+func (s *storedSession[TStored, TValue]) SetAttr(name string, v any) {
+	if a, ok := s.original.(Attributer); ok {
+		a.SetAttr(name, v)
+	}
+}
+
+func (s *storedSession[TStored, TValue]) GetAttr(name string) (any, bool) {
+	if a, ok := s.original.(Attributer); ok {
+		return a.GetAttr(name)
+	}
+	return nil, false
+}
+
+func (s *storedSession[TStored, TValue]) UpdateAttr(name string, f func(v any, exist bool) any) {
+	if a, ok := s.original.(Attributer); ok {
+		a.UpdateAttr(name, f)
+	}
+}
+
+func (s *storedSession[TStored, TValue]) DeleteAttr(name string) {
+	if a, ok := s.original.(Attributer); ok {
+		a.DeleteAttr(name)
+	}
+}
+
+func (s *storedSession[TStored, TValue]) Attrs() map[string]any {
+	if a, ok := s.original.(Attributer); ok {
+		return a.Attrs()
+	}
+	return nil
+}
+
+//Tokener
+
+func (s *storedSession[TStored, TValue]) IsToken() bool {
+	if t, ok := s.original.(Tokener); ok {
+		return t.IsToken()
+	}
+	return false
+}
+
+func (s *storedSession[TStored, TValue]) TokenScopes() []string {
+	if t, ok := s.original.(Tokener); ok {
+		return t.TokenScopes()
+	}
+	return nil
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Returns the session to be handed to a wrapped Backend in place of s, stored under the key with the value supplied
+func storeAs[TStored, TValue any](s ISession[TValue], key string, value TStored) ISession[TStored] {
+	return &storedSession[TStored, TValue]{original: s, key: key, value: value}
+}
+
+//Returns a session to be handed to a Backend when there is nothing but the value stored under the key, e.g. while
+//copying it between backends
+func detachedAs[TValue any](key string, value TValue) ISession[TValue] {
+	return storeAs[TValue, TValue](&Session[TValue]{session[TValue]{Uid: key, Value: value}}, key, value)
+}
+
+//Saves the session to the backend, resolving version conflicts with Requirements.ResolveConflict
+func (ss *SessionStore[TValue]) saveToBackend(s *Session[TValue], dirtyFields []string) error {
+	r := ss.req()
+	b := r.Backend
+
+	for attempt := 0; ; attempt++ {
+		var version uint64
+		err := ss.protect("Backend.Save", func() (err error) {
+			version, err = b.Save(s, dirtyFields, s.Version())
+			return err
+		})
+		if err == nil {
+			s.mx.Lock()
+			s.session.version = version
+			s.mx.Unlock()
+			return nil
+		}
+
+		//A session that was never stored can only conflict if its UID is taken by another node
+		if errors.Is(err, ErrVersionConflict) && r.LazyUidCheck && s.Version() == 0 && attempt < maxConflictRetries {
+			ss.stats.collision()
+			if err := ss.reassignUid(s); err != nil {
+				return err
+			}
+			s.notify(ChangeRegenerated)
+			continue
+		}
+
+		if !errors.Is(err, ErrVersionConflict) || r.ResolveConflict == nil || attempt >= maxConflictRetries {
+			return err
+		}
+
+		var remote TValue
+		var remoteVersion uint64
+		err = ss.protect("Backend.Load", func() (err error) {
+			remote, remoteVersion, err = b.Load(s.StorageKey())
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.resolve(func() error {
+			return ss.protect("ResolveConflict", func() error {
+				s.session.Value = r.ResolveConflict(s.session.Value, remote)
+				return nil
+			})
+		}, remoteVersion)
+
+		if err != nil {
+			return err
+		}
+
+		//The merged value is what readers see from now on
+		ss.reindex(s)
+		s.notify(ChangeValue)
+
+		//The merged value can differ from the remote one in any field, so it's written as a whole
+		dirtyFields = nil
+	}
+}
+
+//FlushToBackend writes every modified session to Requirements.Backend. Sessions modified by other nodes in the
+//meantime are merged using Requirements.ResolveConflict
+func (ss *SessionStore[TValue]) FlushToBackend() error {
+	ss.ready()
+
+	if ss.req().Backend == nil {
+		return ErrNoBackend
+	}
+
+	if err := ss.writable(); err != nil {
+		return err
+	}
+
+	return ss.flush(ss.saveToBackend)
+}
+
+//Runs the conflict resolution under the session lock and adopts the remote version when it succeeds. The lock is
+//released even when a panic propagates
+func (s *Session[TValue]) resolve(merge func() error, remoteVersion uint64) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if err := merge(); err != nil {
+		return err
+	}
+
+	s.session.version = remoteVersion
+	s.resizeQuota()
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_FlushToBackend(t *testing.T) {
+	backend := newTestBackend()
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Backend:         backend,
+		ResolveConflict: func(local, remote string) string { return remote + "+" + local },
+	})
+
+	s := ss.New("a").(*Session[string])
+	if err := ss.FlushToBackend(); err != nil {
+		t.Fatalf("Expected flush to succeed, got %s", err)
+	}
+
+	//Simulating a write from another node
+	backend.records[s.Uid()] = testBackendRecord{"b", 5}
+
+	s.Update(func(v *string) { *v = "c" })
+	if err := ss.FlushToBackend(); err != nil {
+		t.Fatalf("Expected flush with a conflict to succeed, got %s", err)
+	}
+
+	if s.Value() != "b+c" {
+		t.Errorf("Expected the conflict to be resolved into \"b+c\", got \"%s\"", s.Value())
+	}
+
+	if s.Version() != 6 {
+		t.Errorf("Expected version 6 after resolving the conflict, got %d", s.Version())
+	}
+}
+
+func TestSessionStore_FlushOrdering(t *testing.T) {
+	backend := &orderingBackend{inFlight: map[string]bool{}, lastModified: map[string]time.Time{}}
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: backend})
+	s := ss.New("value").(*Session[string])
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				s.Update(func(v *string) { *v += "." })
+				_ = ss.FlushToBackend()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if backend.violations != 0 {
+		t.Errorf("Expected writes of the same session to be serialized and ordered, got %d violations", backend.violations)
+	}
+}
+
+//Backend reporting the dirty fields it's asked to save and wrapping version conflicts
+type fieldsBackend struct {
+	*testBackend
+	fields [][]string
+}
+
+func (b *fieldsBackend) Save(s ISession[string], dirtyFields []string, expectedVersion uint64) (uint64, error) {
+	b.fields = append(b.fields, dirtyFields)
+
+	version, err := b.testBackend.Save(s, dirtyFields, expectedVersion)
+	if err != nil {
+		return 0, fmt.Errorf("fields backend: %w", err)
+	}
+
+	return version, nil
+}
+
+func TestSessionStore_FlushNeverStored(t *testing.T) {
+	backend := &fieldsBackend{testBackend: newTestBackend()}
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Backend: backend,
+		Differ:  func(old, new string) []string { return []string{"Text"} },
+	})
+
+	s := ss.New("a")
+	s.SetValue("b")
+
+	if err := ss.FlushToBackend(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backend.fields) != 1 || backend.fields[0] != nil {
+		t.Errorf("Expected a session never stored to be written as a whole, got %v", backend.fields)
+	}
+}
+
+func TestSessionStore_ResolveWrappedConflict(t *testing.T) {
+	backend := &fieldsBackend{testBackend: newTestBackend()}
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Backend:         backend,
+		ResolveConflict: func(local, remote string) string { return remote + "+" + local },
+	})
+
+	s := ss.New("a").(*Session[string])
+	if err := ss.FlushToBackend(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := s.Watch(ctx)
+
+	backend.records[s.Uid()] = testBackendRecord{"b", 5}
+	s.SetValue("c")
+	<-events
+
+	if err := ss.FlushToBackend(); err != nil {
+		t.Fatalf("Expected a wrapped conflict to be resolved, got %s", err)
+	}
+
+	select {
+	case ev := <-events:
+		if ev.Kind != ChangeValue || ev.Value != "b+c" {
+			t.Errorf("Expected watchers to be told about the merged value, got %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected watchers to be notified of the merged value")
+	}
+}
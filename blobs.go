@@ -0,0 +1,295 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/emillis/idGen"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//ErrNoBlobStorage is returned when blobs are used, but Requirements.BlobStorage is not set
+var ErrNoBlobStorage = errors.New("sessions: blob storage is not set")
+
+//ErrBlobNotFound is returned when the session has no blob with the name requested
+var ErrBlobNotFound = errors.New("sessions: blob not found")
+
+//===========[INTERFACES]====================================================================================================
+
+//BlobStorage stores artifacts attached to sessions, e.g. files uploaded before a form is submitted
+type BlobStorage interface {
+	//Put stores the content under the key, replacing anything stored there before
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	//Get returns the content stored under the key. The caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	//Delete removes the content stored under the key. Deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+//===========[STRUCTS]====================================================================================================
+
+//FileBlobStorage keeps blobs as files within a directory
+type FileBlobStorage struct {
+	//Directory where the files are kept
+	Dir string
+}
+
+//Returns the path of the file for the key, making sure it can't escape the directory
+func (fs *FileBlobStorage) path(key string) (string, error) {
+	p := filepath.Join(fs.Dir, filepath.FromSlash(key))
+
+	if !strings.HasPrefix(p, filepath.Clean(fs.Dir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("sessions: invalid blob key %q", key)
+	}
+
+	return p, nil
+}
+
+//Put writes the content to a file. It is written to a temporary file first, so readers never see partial content
+func (fs *FileBlobStorage) Put(_ context.Context, key string, r io.Reader) error {
+	p, err := fs.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), p)
+}
+
+//Get opens the file of the key
+func (fs *FileBlobStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	p, err := fs.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+
+	return f, err
+}
+
+//Delete removes the file of the key together with its directory if it becomes empty
+func (fs *FileBlobStorage) Delete(_ context.Context, key string) error {
+	p, err := fs.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	//Fails if the directory still has files in it, which is fine
+	_ = os.Remove(filepath.Dir(p))
+
+	return nil
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Returns the storage key of the blob. Blobs are stored under a random prefix rather than the UID, so storage listings
+//don't leak usable session IDs
+func blobKey(prefix, name string) string {
+	return prefix + "/" + name
+}
+
+//AttachBlob stores the content under the name and attaches it to the session. Attached blobs are deleted when the
+//session is removed or expires
+func (s *Session[TValue]) AttachBlob(name string, r io.Reader) error {
+	if s.store == nil || s.store.req().BlobStorage == nil {
+		return ErrNoBlobStorage
+	}
+
+	if err := s.store.writable(); err != nil {
+		return err
+	}
+
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("sessions: invalid blob name %q", name)
+	}
+
+	s.mx.Lock()
+	if s.session.blobPrefix == "" {
+		s.session.blobPrefix = idGen.Random(&idGen.Config{Length: 32})
+	}
+	prefix := s.session.blobPrefix
+	s.mx.Unlock()
+
+	if err := s.store.req().BlobStorage.Put(context.Background(), blobKey(prefix, name), r); err != nil {
+		return err
+	}
+
+	s.mx.Lock()
+	if s.session.blobs == nil {
+		s.session.blobs = make(map[string]struct{})
+	}
+	s.session.blobs[name] = struct{}{}
+	s.touch()
+	s.mx.Unlock()
+
+	s.store.trackBlobs(s.StorageKey(), s)
+
+	return nil
+}
+
+//Blob returns content of the blob attached under the name. The caller closes it
+func (s *Session[TValue]) Blob(name string) (io.ReadCloser, error) {
+	if s.store == nil || s.store.req().BlobStorage == nil {
+		return nil, ErrNoBlobStorage
+	}
+
+	s.mx.RLock()
+	_, exist := s.session.blobs[name]
+	prefix := s.session.blobPrefix
+	s.mx.RUnlock()
+
+	if !exist {
+		return nil, ErrBlobNotFound
+	}
+
+	return s.store.req().BlobStorage.Get(context.Background(), blobKey(prefix, name))
+}
+
+//Blobs returns sorted names of the blobs attached to the session
+func (s *Session[TValue]) Blobs() []string {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return sortedFields(s.session.blobs)
+}
+
+//DetachBlob deletes the blob attached under the name
+func (s *Session[TValue]) DetachBlob(name string) error {
+	if s.store == nil || s.store.req().BlobStorage == nil {
+		return ErrNoBlobStorage
+	}
+
+	if err := s.store.writable(); err != nil {
+		return err
+	}
+
+	s.mx.Lock()
+	_, exist := s.session.blobs[name]
+	delete(s.session.blobs, name)
+	prefix := s.session.blobPrefix
+	if exist {
+		s.touch()
+	}
+	s.mx.Unlock()
+
+	if !exist {
+		return nil
+	}
+
+	return s.store.req().BlobStorage.Delete(context.Background(), blobKey(prefix, name))
+}
+
+//Deletes every blob attached to the session
+func (s *Session[TValue]) deleteBlobs(storage BlobStorage) error {
+	s.mx.Lock()
+	names := sortedFields(s.session.blobs)
+	s.session.blobs = nil
+	prefix := s.session.blobPrefix
+	s.mx.Unlock()
+
+	var errs []error
+	for _, name := range names {
+		if err := storage.Delete(context.Background(), blobKey(prefix, name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+//Remembers the session as an owner of blobs and makes sure the sweep is running
+func (ss *SessionStore[TValue]) trackBlobs(key string, s *Session[TValue]) {
+	ss._blobOwners.Add(key, s)
+
+	ss.mx.Lock()
+	if !ss.blobSweepRunning {
+		ss.blobSweepRunning = true
+		ss.clock.AfterFunc(ss.Requirements.BlobSweepInterval, ss.sweepBlobs)
+	}
+	ss.mx.Unlock()
+}
+
+//Collects blobs periodically for as long as there are sessions owning them
+func (ss *SessionStore[TValue]) sweepBlobs() {
+	if err := ss.CollectBlobs(); err != nil {
+		ss.reportError(err)
+	}
+
+	ss.mx.Lock()
+	defer ss.mx.Unlock()
+
+	if ss._blobOwners.Count() == 0 {
+		ss.blobSweepRunning = false
+		return
+	}
+
+	ss.clock.AfterFunc(ss.Requirements.BlobSweepInterval, ss.sweepBlobs)
+}
+
+//CollectBlobs deletes blobs of sessions that were removed or expired. It runs automatically every
+//Requirements.BlobSweepInterval, but can be called to collect them right away
+func (ss *SessionStore[TValue]) CollectBlobs() error {
+	ss.ready()
+
+	storage := ss.req().BlobStorage
+	if storage == nil {
+		return nil
+	}
+
+	owners := ss._blobOwners.GetAll()
+	keys := make([]string, 0, len(owners))
+	for key := range owners {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		s := owners[key]
+		if s.stored(key) {
+			continue
+		}
+
+		if err := s.deleteBlobs(storage); err != nil {
+			errs = append(errs, fmt.Errorf("sessions: deleting blobs: %w", err))
+			continue
+		}
+
+		ss._blobOwners.Remove(key)
+	}
+
+	return errors.Join(errs...)
+}
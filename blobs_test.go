@@ -0,0 +1,42 @@
+package sessions
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSession_AttachBlob(t *testing.T) {
+	storage := &FileBlobStorage{Dir: t.TempDir()}
+	ss := initializeSessionStore(0, &Requirements[string]{BlobStorage: storage})
+	s := ss.New("value").(*Session[string])
+
+	if err := s.AttachBlob("upload.txt", strings.NewReader("hi mom!")); err != nil {
+		t.Fatalf("Expected blob to be attached, got %s", err)
+	}
+
+	if err := s.AttachBlob("../escape", strings.NewReader("")); err == nil {
+		t.Errorf("Expected blob name with a path separator to be rejected")
+	}
+
+	r, err := s.Blob("upload.txt")
+	if err != nil {
+		t.Fatalf("Expected blob to be readable, got %s", err)
+	}
+	b, _ := io.ReadAll(r)
+	r.Close()
+
+	if string(b) != "hi mom!" {
+		t.Errorf("Expected blob content \"hi mom!\", got \"%s\"", b)
+	}
+
+	prefix := s.session.blobPrefix
+	ss.Remove(s.Uid())
+
+	if _, err := storage.Get(context.Background(), blobKey(prefix, "upload.txt")); err != ErrBlobNotFound {
+		t.Errorf("Expected blob to be deleted with the session, got %v", err)
+	}
+}
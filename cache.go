@@ -0,0 +1,333 @@
+package sessions
+
+import (
+	"container/heap"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+const (
+	//CacheBuiltin is the dependency-free cache of this package. It's the default
+	CacheBuiltin CacheImplementation = iota
+
+	//CacheMachine is github.com/emillis/cacheMachine. It's only available when built with the sessions_cachemachine
+	//build tag and is kept for the transition period
+	CacheMachine
+)
+
+//Number of shards of the built-in cache. Each of them has its own lock
+const cacheShards = 16
+
+//===========[INTERFACES]====================================================================================================
+
+//Storage for the internal caches of the SessionStore
+type cache[TValue any] interface {
+	//Add inserts the value, replacing whatever was stored under the key, without a timeout
+	Add(key string, v TValue)
+
+	//AddWithTimeout inserts the value and removes it after the timeout. 0 means no timeout
+	AddWithTimeout(key string, v TValue, timeout time.Duration)
+
+	//AddTimer sets or resets the timeout of the key
+	AddTimer(key string, timeout time.Duration)
+
+	//StopTimer cancels the timeout of the key
+	StopTimer(key string)
+
+	Remove(key string)
+	Get(key string) (TValue, bool)
+	GetValue(key string) TValue
+	GetAll() map[string]TValue
+	Exist(key string) bool
+	Count() int
+	ForEach(f func(string, TValue))
+}
+
+//===========[STRUCTS]====================================================================================================
+
+//CacheImplementation selects the storage of the in-memory caches of the SessionStore
+type CacheImplementation int
+
+//Single value of the built-in cache
+type cacheItem[TValue any] struct {
+	value TValue
+
+	//Identifies the current timeout of the item, so outdated deadlines in the heap are ignored. 0 means no timeout
+	generation uint64
+}
+
+//Part of the built-in cache with its own lock
+type cacheShard[TValue any] struct {
+	items map[string]*cacheItem[TValue]
+	mx    sync.RWMutex
+}
+
+//Scheduled removal of a key
+type cacheDeadline struct {
+	key        string
+	at         time.Time
+	generation uint64
+}
+
+//Min-heap of deadlines, the earliest one on top
+type deadlineHeap []cacheDeadline
+
+func (h deadlineHeap) Len() int           { return len(h) }
+func (h deadlineHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h deadlineHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *deadlineHeap) Push(x any)        { *h = append(*h, x.(cacheDeadline)) }
+func (h *deadlineHeap) Pop() any {
+	old := *h
+	d := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return d
+}
+
+//Built-in cache: sharded maps for the values and a single heap of deadlines for timeouts. Instead of a timer per
+//entry, one timer is armed for the earliest deadline
+type builtinCache[TValue any] struct {
+	shards [cacheShards]cacheShard[TValue]
+
+	//Called with every item removed by its timeout, outside of any lock
+	onExpire func(key string, v TValue)
+
+	clock      clock
+	deadlines  deadlineHeap
+	generation uint64
+	timer      clockTimer
+	timerAt    time.Time
+	mx         sync.Mutex
+}
+
+//------PRIVATE------
+
+//Returns the shard the key belongs to
+func (c *builtinCache[TValue]) shard(key string) *cacheShard[TValue] {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return &c.shards[h.Sum32()%cacheShards]
+}
+
+//Returns new generation for a timeout
+func (c *builtinCache[TValue]) nextGeneration() uint64 {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.generation++
+	return c.generation
+}
+
+//Pushes the deadline into the heap and makes sure the timer fires in time for it
+func (c *builtinCache[TValue]) schedule(d cacheDeadline) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	heap.Push(&c.deadlines, d)
+	c.arm()
+}
+
+//Arms the timer for the earliest deadline. The caller holds the lock
+func (c *builtinCache[TValue]) arm() {
+	if len(c.deadlines) == 0 {
+		return
+	}
+
+	next := c.deadlines[0].at
+	if c.timer != nil && !c.timerAt.After(next) {
+		return
+	}
+
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+
+	c.timerAt = next
+	c.timer = c.clock.AfterFunc(next.Sub(c.clock.Now()), c.expire)
+}
+
+//Removes every item whose deadline has passed
+func (c *builtinCache[TValue]) expire() {
+	type expired struct {
+		key   string
+		value TValue
+	}
+
+	var removed []expired
+	now := c.clock.Now()
+
+	c.mx.Lock()
+	c.timer = nil
+
+	for len(c.deadlines) > 0 && !c.deadlines[0].at.After(now) {
+		d := heap.Pop(&c.deadlines).(cacheDeadline)
+
+		s := c.shard(d.key)
+		s.mx.Lock()
+		if it, exist := s.items[d.key]; exist && it.generation == d.generation {
+			delete(s.items, d.key)
+			removed = append(removed, expired{d.key, it.value})
+		}
+		s.mx.Unlock()
+	}
+
+	c.arm()
+	c.mx.Unlock()
+
+	if c.onExpire == nil {
+		return
+	}
+
+	for _, e := range removed {
+		c.onExpire(e.key, e.value)
+	}
+}
+
+//------PUBLIC------
+
+//Add inserts the value without a timeout
+func (c *builtinCache[TValue]) Add(key string, v TValue) {
+	c.AddWithTimeout(key, v, 0)
+}
+
+//AddWithTimeout inserts the value and removes it after the timeout. 0 means no timeout
+func (c *builtinCache[TValue]) AddWithTimeout(key string, v TValue, timeout time.Duration) {
+	it := &cacheItem[TValue]{value: v}
+
+	if timeout != 0 {
+		it.generation = c.nextGeneration()
+	}
+
+	s := c.shard(key)
+	s.mx.Lock()
+	s.items[key] = it
+	s.mx.Unlock()
+
+	if timeout != 0 {
+		c.schedule(cacheDeadline{key: key, at: c.clock.Now().Add(timeout), generation: it.generation})
+	}
+}
+
+//AddTimer sets or resets the timeout of the key. Missing keys are ignored
+func (c *builtinCache[TValue]) AddTimer(key string, timeout time.Duration) {
+	generation := c.nextGeneration()
+
+	s := c.shard(key)
+	s.mx.Lock()
+	it, exist := s.items[key]
+	if exist {
+		it.generation = generation
+	}
+	s.mx.Unlock()
+
+	if exist {
+		c.schedule(cacheDeadline{key: key, at: c.clock.Now().Add(timeout), generation: generation})
+	}
+}
+
+//StopTimer cancels the timeout of the key
+func (c *builtinCache[TValue]) StopTimer(key string) {
+	s := c.shard(key)
+	s.mx.Lock()
+	if it, exist := s.items[key]; exist {
+		it.generation = 0
+	}
+	s.mx.Unlock()
+}
+
+//Remove deletes the key
+func (c *builtinCache[TValue]) Remove(key string) {
+	s := c.shard(key)
+	s.mx.Lock()
+	delete(s.items, key)
+	s.mx.Unlock()
+}
+
+//Get returns the value and whether it exists
+func (c *builtinCache[TValue]) Get(key string) (TValue, bool) {
+	s := c.shard(key)
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	if it, exist := s.items[key]; exist {
+		return it.value, true
+	}
+
+	var zero TValue
+	return zero, false
+}
+
+//GetValue returns the value or zero value if it doesn't exist
+func (c *builtinCache[TValue]) GetValue(key string) TValue {
+	v, _ := c.Get(key)
+	return v
+}
+
+//GetAll returns a copy of every key:value pair
+func (c *builtinCache[TValue]) GetAll() map[string]TValue {
+	all := make(map[string]TValue)
+
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mx.RLock()
+		for k, it := range s.items {
+			all[k] = it.value
+		}
+		s.mx.RUnlock()
+	}
+
+	return all
+}
+
+//Exist checks whether the key exists
+func (c *builtinCache[TValue]) Exist(key string) bool {
+	s := c.shard(key)
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	_, exist := s.items[key]
+	return exist
+}
+
+//Count returns number of stored items
+func (c *builtinCache[TValue]) Count() int {
+	n := 0
+
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mx.RLock()
+		n += len(s.items)
+		s.mx.RUnlock()
+	}
+
+	return n
+}
+
+//ForEach runs the function for a copy of every key:value pair, so the cache isn't locked meanwhile
+func (c *builtinCache[TValue]) ForEach(f func(string, TValue)) {
+	for k, v := range c.GetAll() {
+		f(k, v)
+	}
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Creates a built-in cache running on the clock. onExpire is optional
+func newBuiltinCache[TValue any](clk clock, onExpire func(key string, v TValue)) *builtinCache[TValue] {
+	c := &builtinCache[TValue]{clock: clk, onExpire: onExpire}
+
+	for i := range c.shards {
+		c.shards[i].items = make(map[string]*cacheItem[TValue])
+	}
+
+	return c
+}
+
+//Creates the cache selected in the Requirements. onExpire is optional and only supported by CacheBuiltin
+func newCache[TValue any](impl CacheImplementation, clk clock, onExpire func(key string, v TValue)) cache[TValue] {
+	if impl == CacheMachine && cacheMachineAvailable {
+		return newCacheMachineCache[TValue]()
+	}
+
+	return newBuiltinCache[TValue](clk, onExpire)
+}
@@ -0,0 +1,44 @@
+//go:build sessions_cachemachine
+
+package sessions
+
+import (
+	"github.com/emillis/cacheMachine"
+	"time"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//Whether CacheMachine can be selected in this build
+const cacheMachineAvailable = true
+
+//===========[STRUCTS]====================================================================================================
+
+//Adapts cacheMachine.Cache to the cache interface
+type cacheMachineCache[TValue any] struct {
+	cacheMachine.Cache[string, TValue]
+}
+
+//Add inserts the value without a timeout
+func (c *cacheMachineCache[TValue]) Add(key string, v TValue) {
+	c.Cache.Add(key, v)
+}
+
+//AddWithTimeout inserts the value and removes it after the timeout. 0 means no timeout
+func (c *cacheMachineCache[TValue]) AddWithTimeout(key string, v TValue, timeout time.Duration) {
+	c.Cache.AddWithTimeout(key, v, timeout)
+}
+
+//StopTimer cancels the timeout of the key
+func (c *cacheMachineCache[TValue]) StopTimer(key string) {
+	if e := c.Cache.GetEntry(key); e != nil {
+		e.StopTimer()
+	}
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Creates a cache backed by cacheMachine
+func newCacheMachineCache[TValue any]() cache[TValue] {
+	return &cacheMachineCache[TValue]{cacheMachine.New[string, TValue](nil)}
+}
@@ -0,0 +1,15 @@
+//go:build !sessions_cachemachine
+
+package sessions
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//Whether CacheMachine can be selected in this build
+const cacheMachineAvailable = false
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Never called in this build, CacheMachine is not available
+func newCacheMachineCache[TValue any]() cache[TValue] {
+	return nil
+}
@@ -0,0 +1,45 @@
+package sessions
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestBuiltinCache_Timeout(t *testing.T) {
+	var expired []string
+	var mx sync.Mutex
+
+	clk := &fakeClock{now: time.Now()}
+	c := newBuiltinCache[int](clk, func(key string, _ int) {
+		mx.Lock()
+		expired = append(expired, key)
+		mx.Unlock()
+	})
+
+	c.AddWithTimeout("short", 1, 10*time.Millisecond)
+	c.AddWithTimeout("reset", 2, 10*time.Millisecond)
+	c.AddWithTimeout("stopped", 3, 10*time.Millisecond)
+	c.Add("forever", 4)
+
+	c.AddTimer("reset", time.Hour)
+	c.StopTimer("stopped")
+
+	clk.Advance(40 * time.Millisecond)
+
+	if c.Exist("short") {
+		t.Errorf("Expected \"short\" to be removed by its timeout")
+	}
+
+	if !c.Exist("reset") || !c.Exist("stopped") || !c.Exist("forever") {
+		t.Errorf("Expected \"reset\", \"stopped\" and \"forever\" to stay, got %v", c.GetAll())
+	}
+
+	mx.Lock()
+	defer mx.Unlock()
+	if len(expired) != 1 || expired[0] != "short" {
+		t.Errorf("Expected onExpire to be called for \"short\" only, got %v", expired)
+	}
+}
@@ -0,0 +1,160 @@
+package sessions
+
+import (
+	"context"
+	"golang.org/x/text/language"
+	"golang.org/x/time/rate"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+//===========[INTERFACES]====================================================================================================
+
+//Capabilities are optional parts of a session, discovered with a type assertion, e.g. s.(sessions.Expirer). They keep
+//ISession small, so custom session implementations only need to provide what they actually support. Sessions created
+//by SessionStore implement all of them
+
+//Expirer is implemented by sessions that can have an absolute deadline and have their expiry suspended
+type Expirer interface {
+	ExpiresAt() time.Time
+	ExpireAt(t time.Time)
+	SuspendExpiry() func()
+}
+
+//Regenerator is implemented by sessions whose UID can be replaced with a fresh one, e.g. after login, to prevent
+//session fixation
+type Regenerator interface {
+	Regenerate() string
+	RegenerateE() (string, error)
+}
+
+//Indexer is implemented by sessions that expose fields they can be looked up by
+type Indexer interface {
+	IndexFields() map[string]string
+}
+
+//Attributer is implemented by sessions that keep attributes apart from their value. See AttrKey for the typed API
+type Attributer interface {
+	SetAttr(name string, v any)
+	GetAttr(name string) (any, bool)
+	UpdateAttr(name string, f func(v any, exist bool) any)
+	DeleteAttr(name string)
+	Attrs() map[string]any
+}
+
+//Localizer is implemented by sessions that keep the locale and the time zone of the user
+type Localizer interface {
+	SetLocale(tag language.Tag)
+	Locale() language.Tag
+	SetTimezone(loc *time.Location)
+	Timezone() *time.Location
+}
+
+//Checkpointer is implemented by sessions whose value can be rolled back to an earlier snapshot
+type Checkpointer interface {
+	Checkpoint() CheckpointID
+	Rollback(id CheckpointID) error
+}
+
+//Updater is implemented by sessions whose value can be modified in place, reporting rejected values, and that track
+//which fields were modified
+type Updater[TValue any] interface {
+	Update(f func(v *TValue))
+	UpdateE(f func(v *TValue)) error
+	SetValueE(v TValue) error
+	DirtyFields() []string
+}
+
+//Describer is implemented by sessions that expose metadata kept by the store
+type Describer interface {
+	CreatedAt() time.Time
+	Version() uint64
+	StorageKey() string
+	RoutingKey() string
+	Label() string
+	Priority() Priority
+}
+
+//CookieWriter is implemented by sessions that can write their own cookie
+type CookieWriter interface {
+	SetHttpCookie(w http.ResponseWriter, cookie *http.Cookie)
+	CookieHeaderValue() string
+	CookieStale() bool
+}
+
+//BlobHolder is implemented by sessions that can have blobs attached
+type BlobHolder interface {
+	AttachBlob(name string, r io.Reader) error
+	Blob(name string) (io.ReadCloser, error)
+	Blobs() []string
+	DetachBlob(name string) error
+}
+
+//Watchable is implemented by sessions that report their changes
+type Watchable[TValue any] interface {
+	Watch(ctx context.Context) <-chan ChangeEvent[TValue]
+}
+
+//Scoper is implemented by sessions that keep namespaced values next to the main one
+type Scoper[TValue any] interface {
+	Scope(name string) *Scope[TValue]
+}
+
+//Limiter is implemented by sessions that rate limit actions
+type Limiter interface {
+	Allow(action string, limit rate.Limit, burst int) bool
+}
+
+//Spawner is implemented by sessions that can create child sessions bound to their lifetime
+type Spawner[TValue any] interface {
+	NewChild(data TValue) (ISession[TValue], error)
+	Parent() ISession[TValue]
+	Children() []ISession[TValue]
+}
+
+//Tokener is implemented by sessions that can be API tokens, see SessionStore.NewToken
+type Tokener interface {
+	IsToken() bool
+	TokenScopes() []string
+}
+
+//LoggerProvider is implemented by sessions that can annotate a logger with their identity
+type LoggerProvider interface {
+	Logger(base *slog.Logger) *slog.Logger
+}
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//Sessions created by SessionStore must keep implementing every capability
+var (
+	_ Expirer        = (*Session[any])(nil)
+	_ Regenerator    = (*Session[any])(nil)
+	_ Indexer        = (*Session[any])(nil)
+	_ Attributer     = (*Session[any])(nil)
+	_ Localizer      = (*Session[any])(nil)
+	_ Checkpointer   = (*Session[any])(nil)
+	_ Updater[any]   = (*Session[any])(nil)
+	_ Describer      = (*Session[any])(nil)
+	_ CookieWriter   = (*Session[any])(nil)
+	_ BlobHolder     = (*Session[any])(nil)
+	_ Watchable[any] = (*Session[any])(nil)
+	_ Scoper[any]    = (*Session[any])(nil)
+	_ Limiter        = (*Session[any])(nil)
+	_ LoggerProvider = (*Session[any])(nil)
+	_ Spawner[any]   = (*Session[any])(nil)
+	_ Tokener        = (*Session[any])(nil)
+)
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//StorageKeyOf returns the key the session is stored under. Sessions that don't implement Describer are stored under
+//their UID
+func StorageKeyOf[TValue any](s ISession[TValue]) string {
+	if d, ok := s.(Describer); ok {
+		return d.StorageKey()
+	}
+
+	return s.Uid()
+}
@@ -0,0 +1,40 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSession_Capabilities(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{
+		TombstoneTimeout: time.Minute,
+		Index:            func(v string) map[string]string { return map[string]string{"user": v} },
+	})
+	s := ss.New("42")
+
+	r, ok := s.(Regenerator)
+	if !ok {
+		t.Fatalf("Expected session to implement Regenerator")
+	}
+
+	oldUid := s.Uid()
+	newUid := r.Regenerate()
+
+	if newUid == oldUid || s.Uid() != newUid {
+		t.Errorf("Expected session to get a new UID")
+	}
+
+	if ss.Get(oldUid) != nil || !ss.IsRevoked(oldUid) {
+		t.Errorf("Expected old UID to stop working after regeneration")
+	}
+
+	if ss.Get(newUid) != s {
+		t.Errorf("Expected session to be found under the new UID")
+	}
+
+	if i, ok := s.(Indexer); !ok || i.IndexFields()["user"] != "42" {
+		t.Errorf("Expected session to be indexed by user 42")
+	}
+}
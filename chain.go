@@ -0,0 +1,220 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//WritePolicy decides which backends of a ChainBackend sessions are written to
+type WritePolicy int
+
+const (
+	//WriteAll writes to every backend in the chain. The first error is returned
+	WriteAll WritePolicy = iota
+
+	//WritePrimary writes to the primary backend only. Secondary ones are read from, e.g. a legacy store sessions are
+	//being migrated from, and are only cleaned up by Remove
+	WritePrimary
+
+	//WritePrimaryBestEffort writes to every backend, but only errors of the primary one are returned. Errors of the
+	//secondary ones go to ChainBackend.OnError
+	WritePrimaryBestEffort
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//ChainBackend is a Backend made of several others, e.g. Redis in front of SQL. Together with the in-memory cache of
+//SessionStore, it makes a read-through chain memory → Redis → SQL
+type ChainBackend[TValue any] struct {
+	//Backends in the order they are read from. The first one is the primary
+	backends []Backend[TValue]
+
+	//Policy decides which backends are written to. Defaults to WriteAll
+	Policy WritePolicy
+
+	//OnError receives errors of secondary backends that aren't returned, see WritePrimaryBestEffort
+	OnError func(err error)
+
+	//Last known versions of sessions in the secondary backends, keyed by storage key. The session itself only tracks
+	//the version of the primary one
+	versions map[string][]uint64
+
+	mx sync.Mutex
+}
+
+//Load returns the session from the first backend that has it. Sessions found in a secondary backend are copied to
+//the backends before it, so they are found sooner next time
+func (c *ChainBackend[TValue]) Load(key string) (TValue, uint64, error) {
+	var zero TValue
+
+	for i, b := range c.backends {
+		value, version, err := b.Load(key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return zero, 0, err
+		}
+
+		if i > 0 {
+			c.setVersion(key, i, version)
+			value, version = c.promote(key, value, i)
+		}
+
+		return value, version, nil
+	}
+
+	return zero, 0, ErrNotFound
+}
+
+//Save writes the session according to Policy. The version returned is the one of the primary backend
+func (c *ChainBackend[TValue]) Save(s ISession[TValue], dirtyFields []string, expectedVersion uint64) (uint64, error) {
+	version, err := c.backends[0].Save(s, dirtyFields, expectedVersion)
+	if err != nil || c.Policy == WritePrimary {
+		return version, err
+	}
+
+	for i := 1; i < len(c.backends); i++ {
+		if err := c.saveSecondary(s, dirtyFields, i); err != nil {
+			if c.Policy == WriteAll {
+				return version, err
+			}
+			c.reportError(err)
+		}
+	}
+
+	return version, nil
+}
+
+//Remove deletes the session from every backend in the chain, regardless of Policy
+func (c *ChainBackend[TValue]) Remove(key string) error {
+	var errs []error
+
+	for _, b := range c.backends {
+		if err := b.Remove(key); err != nil && !errors.Is(err, ErrNotFound) {
+			errs = append(errs, err)
+		}
+	}
+
+	c.forget(key)
+
+	return errors.Join(errs...)
+}
+
+//SessionExpired drops the versions kept for the session, see ExpiryListener
+func (c *ChainBackend[TValue]) SessionExpired(key string) {
+	c.forget(key)
+
+	for _, b := range c.backends {
+		if l, ok := b.(ExpiryListener); ok {
+			l.SessionExpired(key)
+		}
+	}
+}
+
+//Drops the versions kept for the session
+func (c *ChainBackend[TValue]) forget(key string) {
+	c.mx.Lock()
+	delete(c.versions, key)
+	c.mx.Unlock()
+}
+
+//Writes the session to the secondary backend. The primary backend is the source of truth, so a conflict is
+//resolved by overwriting whatever the secondary one holds
+func (c *ChainBackend[TValue]) saveSecondary(s ISession[TValue], dirtyFields []string, i int) error {
+	b := c.backends[i]
+	key := StorageKeyOf(s)
+
+	version, err := b.Save(s, dirtyFields, c.version(key, i))
+	if errors.Is(err, ErrVersionConflict) {
+		var current uint64
+		if _, current, err = b.Load(key); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+
+		version, err = b.Save(s, nil, current)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.setVersion(key, i, version)
+
+	return nil
+}
+
+//Copies the value found in the backend i to the backends before it. Returns the value and the version the primary
+//backend holds afterwards, as the session tracks the version of the primary one. If the session was written to the
+//primary backend in the meantime, that's what is returned. If the primary one couldn't be written, the version is 0,
+//so the next Save creates the session there
+func (c *ChainBackend[TValue]) promote(key string, value TValue, i int) (TValue, uint64) {
+	s := detachedAs(key, value)
+
+	for j := i - 1; j > 0; j-- {
+		if err := c.saveSecondary(s, nil, j); err != nil {
+			c.reportError(fmt.Errorf("sessions: promoting session in chain: %w", err))
+		}
+	}
+
+	//The primary backend didn't have the session when it was looked up
+	version, err := c.backends[0].Save(s, nil, 0)
+	if errors.Is(err, ErrVersionConflict) {
+		var current TValue
+		if current, version, err = c.backends[0].Load(key); err == nil {
+			return current, version
+		}
+	}
+	if err != nil {
+		c.reportError(fmt.Errorf("sessions: promoting session in chain: %w", err))
+		return value, 0
+	}
+
+	return value, version
+}
+
+//Returns the last known version of the session in the backend i
+func (c *ChainBackend[TValue]) version(key string, i int) uint64 {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if v := c.versions[key]; len(v) > i {
+		return v[i]
+	}
+
+	return 0
+}
+
+//Stores the last known version of the session in the backend i
+func (c *ChainBackend[TValue]) setVersion(key string, i int, version uint64) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	v := c.versions[key]
+	if len(v) <= i {
+		v = append(v, make([]uint64, i+1-len(v))...)
+	}
+	v[i] = version
+
+	c.versions[key] = v
+}
+
+//Passes the error to OnError, if set
+func (c *ChainBackend[TValue]) reportError(err error) {
+	if c.OnError != nil {
+		c.OnError(err)
+	}
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Chain returns a Backend that reads through the primary and then the secondary backends in order, and writes to them
+//according to ChainBackend.Policy. Use it as Requirements.Backend
+func Chain[TValue any](primary Backend[TValue], secondary ...Backend[TValue]) *ChainBackend[TValue] {
+	return &ChainBackend[TValue]{
+		backends: append([]Backend[TValue]{primary}, secondary...),
+		versions: make(map[string][]uint64),
+	}
+}
@@ -0,0 +1,87 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestChain(t *testing.T) {
+	redis, sql := newTestBackend(), newTestBackend()
+	sql.records["legacy"] = testBackendRecord{"old", 3}
+
+	chain := Chain[string](redis, sql)
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: chain})
+
+	value, version, err := chain.Load("legacy")
+	if err != nil || value != "old" {
+		t.Fatalf("Expected session to be read through to the secondary backend, got %q, %v", value, err)
+	}
+
+	if r, exist := redis.records["legacy"]; !exist || version != r.version {
+		t.Errorf("Expected session to be promoted to the primary backend with its version returned")
+	}
+
+	s := ss.New("value")
+	if err := ss.FlushToBackend(); err != nil {
+		t.Fatalf("Expected flush to succeed, got %s", err)
+	}
+
+	if _, exist := sql.records[StorageKeyOf(s)]; !exist {
+		t.Errorf("Expected WriteAll to write to the secondary backend")
+	}
+
+	chain.Policy = WritePrimary
+	primaryOnly := ss.New("primary only")
+	_ = ss.FlushToBackend()
+
+	if _, exist := sql.records[StorageKeyOf(primaryOnly)]; exist {
+		t.Errorf("Expected WritePrimary to leave the secondary backend alone")
+	}
+
+	ss.Remove(s.Uid())
+	if len(redis.records) != 2 || len(sql.records) != 1 {
+		t.Errorf("Expected removal from every backend, got %d and %d records left", len(redis.records), len(sql.records))
+	}
+}
+
+func TestChain_PromoteWrittenMeanwhile(t *testing.T) {
+	redis := &hookBackend{testBackend: newTestBackend()}
+	sql := newTestBackend()
+	sql.records["legacy"] = testBackendRecord{"old", 3}
+
+	//Another node writes the session to the primary backend between the lookup and the promotion
+	redis.onSave = func() {
+		redis.onSave = nil
+		redis.records["legacy"] = testBackendRecord{"newer", 7}
+	}
+
+	value, version, err := Chain[string](redis, sql).Load("legacy")
+	if err != nil || value != "newer" || version != 7 {
+		t.Errorf("Expected the value and version of the primary backend, got %q, %d, %v", value, version, err)
+	}
+}
+
+func TestChain_ExpiredVersions(t *testing.T) {
+	chain := Chain[string](newTestBackend(), newTestBackend())
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: chain, Timeout: time.Minute})
+
+	s := ss.New("value")
+	if err := ss.FlushToBackend(); err != nil {
+		t.Fatal(err)
+	}
+	if chain.version(StorageKeyOf(s), 1) == 0 {
+		t.Fatalf("Expected the version of the secondary backend to be kept")
+	}
+
+	clockOf(ss).Advance(2 * time.Minute)
+
+	chain.mx.Lock()
+	left := len(chain.versions)
+	chain.mx.Unlock()
+
+	if left != 0 {
+		t.Errorf("Expected versions of expired sessions to be dropped, got %d left", left)
+	}
+}
@@ -0,0 +1,90 @@
+package sessions
+
+import "errors"
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//ErrCheckpointNotFound is returned by Rollback when the checkpoint doesn't exist or was already dropped
+var ErrCheckpointNotFound = errors.New("sessions: checkpoint not found")
+
+//Checkpoints kept by sessions that don't belong to a store
+const defaultMaxCheckpoints = 8
+
+//===========[STRUCTS]====================================================================================================
+
+//CheckpointID identifies a snapshot taken by Checkpoint within its session
+type CheckpointID uint64
+
+//Snapshot of the session value
+type checkpoint[TValue any] struct {
+	id    CheckpointID
+	value TValue
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Checkpoint takes a snapshot of the value, so it can be restored with Rollback, e.g. when a later step of a wizard
+//fails. Only Requirements.MaxCheckpoints newest snapshots are kept. Snapshots are shallow copies, so pointers, slices
+//and maps inside the value are shared with it. 0 is returned if the value of a hibernated session can't be loaded
+//back, in which case the error goes to Requirements.OnError
+func (s *Session[TValue]) Checkpoint() CheckpointID {
+	limit := defaultMaxCheckpoints
+	if s.store != nil {
+		limit = s.store.req().MaxCheckpoints
+	}
+
+	if err := s.lockAwake(s.mx.Lock, s.mx.Unlock); err != nil {
+		s.store.reportError(err)
+		return 0
+	}
+	defer s.mx.Unlock()
+
+	s.session.nextCheckpoint++
+	id := s.session.nextCheckpoint
+
+	s.session.checkpoints = append(s.session.checkpoints, checkpoint[TValue]{id, s.session.Value})
+	if n := len(s.session.checkpoints); n > limit {
+		s.session.checkpoints = append([]checkpoint[TValue](nil), s.session.checkpoints[n-limit:]...)
+	}
+
+	return id
+}
+
+//Rollback restores the value saved by the checkpoint. Checkpoints taken after it are dropped, the checkpoint itself
+//is kept, so it can be rolled back to again
+func (s *Session[TValue]) Rollback(id CheckpointID) error {
+	s.mx.RLock()
+	value, exist := s.checkpointValue(id)
+	s.mx.RUnlock()
+
+	if !exist {
+		return ErrCheckpointNotFound
+	}
+
+	if err := s.UpdateE(func(v *TValue) { *v = value }); err != nil {
+		return err
+	}
+
+	s.mx.Lock()
+	for i, c := range s.session.checkpoints {
+		if c.id == id {
+			s.session.checkpoints = s.session.checkpoints[:i+1]
+			break
+		}
+	}
+	s.mx.Unlock()
+
+	return nil
+}
+
+//Returns the value saved by the checkpoint. The caller must hold the lock
+func (s *Session[TValue]) checkpointValue(id CheckpointID) (TValue, bool) {
+	for _, c := range s.session.checkpoints {
+		if c.id == id {
+			return c.value, true
+		}
+	}
+
+	var zero TValue
+	return zero, false
+}
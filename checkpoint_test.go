@@ -0,0 +1,26 @@
+package sessions
+
+import "testing"
+
+//===========[TESTING]====================================================================================================
+
+func TestSession_Checkpoint(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{MaxCheckpoints: 2})
+	s := ss.New("step 1").(*Session[string])
+
+	first := s.Checkpoint()
+	s.SetValue("step 2")
+	second := s.Checkpoint()
+	s.SetValue("step 3")
+
+	if err := s.Rollback(second); err != nil || s.Value() != "step 2" {
+		t.Errorf("Expected rollback to \"step 2\", got \"%s\", %v", s.Value(), err)
+	}
+
+	s.Checkpoint()
+	s.Checkpoint()
+
+	if err := s.Rollback(first); err != ErrCheckpointNotFound {
+		t.Errorf("Expected the oldest checkpoint to be dropped, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package sessions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//ChecksummedValue is what ChecksumBackend stores in the backend it wraps: the value together with the SHA-256 checksum
+//of its JSON encoding
+type ChecksummedValue[TValue any] struct {
+	Value    TValue `json:"value" bson:"value"`
+	Checksum string `json:"checksum" bson:"checksum"`
+}
+
+//ChecksumBackend is a Backend that stores a checksum next to every value and verifies it on load, so corrupted
+//records are reported with ErrCorrupted instead of being handed to the application
+type ChecksumBackend[TValue any] struct {
+	backend Backend[ChecksummedValue[TValue]]
+
+	//OnCorrupted is called with the storage key of every record that fails verification, e.g. to alert or to remove
+	//the record. Its panic is returned as PanicError together with ErrCorrupted
+	OnCorrupted func(key string)
+}
+
+//Load returns the value stored under the key, or ErrCorrupted if it doesn't match its checksum
+func (c *ChecksumBackend[TValue]) Load(key string) (TValue, uint64, error) {
+	var zero TValue
+
+	stored, version, err := c.backend.Load(key)
+	if err != nil {
+		return zero, 0, err
+	}
+
+	sum, err := checksum(stored.Value)
+	if err != nil {
+		return zero, 0, err
+	}
+
+	if sum != stored.Checksum {
+		err = fmt.Errorf("%w: checksum mismatch for key %q", ErrCorrupted, key)
+
+		if c.OnCorrupted != nil {
+			if perr := protectWith("OnCorrupted", false, func() error { c.OnCorrupted(key); return nil }); perr != nil {
+				err = errors.Join(err, perr)
+			}
+		}
+
+		return zero, 0, err
+	}
+
+	return stored.Value, version, nil
+}
+
+//Save stores the value together with its checksum. The checksum covers the whole value, so it's always written whole.
+//The wrapped backend gets the session itself with only its value replaced, so whatever else it stores about the
+//session, e.g. its deadline or attributes, is kept
+func (c *ChecksumBackend[TValue]) Save(s ISession[TValue], _ []string, expectedVersion uint64) (uint64, error) {
+	value := s.Value()
+
+	sum, err := checksum(value)
+	if err != nil {
+		return 0, err
+	}
+
+	wrapped := storeAs(s, StorageKeyOf(s), ChecksummedValue[TValue]{Value: value, Checksum: sum})
+
+	return c.backend.Save(wrapped, nil, expectedVersion)
+}
+
+//Remove deletes the record stored under the key
+func (c *ChecksumBackend[TValue]) Remove(key string) error {
+	return c.backend.Remove(key)
+}
+
+//SessionExpired passes the key on to the wrapped backend, see ExpiryListener
+func (c *ChecksumBackend[TValue]) SessionExpired(key string) {
+	if l, ok := c.backend.(ExpiryListener); ok {
+		l.SessionExpired(key)
+	}
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Returns hex encoded SHA-256 of the JSON encoding of the value
+func checksum[TValue any](v TValue) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+//Checksummed wraps the backend, so that values are stored with a checksum and verified on load
+func Checksummed[TValue any](backend Backend[ChecksummedValue[TValue]]) *ChecksumBackend[TValue] {
+	return &ChecksumBackend[TValue]{backend: backend}
+}
@@ -0,0 +1,74 @@
+package sessions
+
+import (
+	"errors"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestChecksummed(t *testing.T) {
+	inner := &mapBackend[ChecksummedValue[string]]{records: make(map[string]ChecksummedValue[string])}
+	backend := Checksummed[string](inner)
+
+	var corrupted []string
+	backend.OnCorrupted = func(key string) { corrupted = append(corrupted, key) }
+
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: backend})
+	s := ss.New("value")
+	if err := ss.FlushToBackend(); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _, err := backend.Load(s.Uid()); err != nil || value != "value" {
+		t.Errorf("Expected intact record to load, got \"%s\", %v", value, err)
+	}
+
+	rec := inner.records[s.Uid()]
+	rec.Value = "tampered"
+	inner.records[s.Uid()] = rec
+
+	if _, _, err := backend.Load(s.Uid()); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("Expected corrupted record to fail with ErrCorrupted, got %v", err)
+	}
+
+	if len(corrupted) != 1 || corrupted[0] != s.Uid() {
+		t.Errorf("Expected OnCorrupted to be called with the key, got %v", corrupted)
+	}
+}
+
+func TestChecksumBackend_Save(t *testing.T) {
+	inner := &recordBackend[ChecksummedValue[string]]{records: map[string]SessionRecord[ChecksummedValue[string]]{}}
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: Checksummed[string](inner), HashUid: SHA256UidHasher})
+
+	s := ss.New("value")
+	s.(Attributer).SetAttr("role", "admin")
+
+	if err := ss.FlushToBackend(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, exist := inner.records[StorageKeyOf(s)]
+	if !exist {
+		t.Fatalf("Expected the session to be stored under its storage key")
+	}
+
+	if rec.Uid != s.Uid() || rec.Value.Value != "value" || rec.Metadata.Attrs["role"] != "admin" {
+		t.Errorf("Expected the wrapped backend to get the session itself, got %+v", rec)
+	}
+}
+
+func TestChecksumBackend_OnCorruptedPanics(t *testing.T) {
+	inner := &mapBackend[ChecksummedValue[string]]{records: map[string]ChecksummedValue[string]{
+		"key": {Value: "tampered", Checksum: "bogus"},
+	}}
+	backend := Checksummed[string](inner)
+	backend.OnCorrupted = func(string) { panic("alert") }
+
+	_, _, err := backend.Load("key")
+
+	var perr *PanicError
+	if !errors.Is(err, ErrCorrupted) || !errors.As(err, &perr) || perr.Callback != "OnCorrupted" {
+		t.Errorf("Expected ErrCorrupted together with the recovered panic, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package sessions
+
+import "time"
+
+//===========[INTERFACES]====================================================================================================
+
+//Source of time for the store and its caches. Everything that measures timeouts goes through it, so tests can move
+//time forward instead of waiting for it
+type clock interface {
+	Now() time.Time
+
+	//AfterFunc calls f in its own goroutine once the duration has passed
+	AfterFunc(d time.Duration, f func()) clockTimer
+}
+
+//Timer returned by clock.AfterFunc
+type clockTimer interface {
+	Stop() bool
+}
+
+//===========[STRUCTS]====================================================================================================
+
+//Clock of the system, used unless tests replace it
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                                 { return time.Now() }
+func (systemClock) AfterFunc(d time.Duration, f func()) clockTimer { return time.AfterFunc(d, f) }
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Now returns the current time as the store sees it, e.g. for timestamps that must agree with its timeouts
+func (ss *SessionStore[TValue]) Now() time.Time {
+	ss.ready()
+
+	return ss.now()
+}
+
+//Returns the current time of the store
+func (ss *SessionStore[TValue]) now() time.Time {
+	if ss.clock == nil {
+		return time.Now()
+	}
+
+	return ss.clock.Now()
+}
+
+//Returns the current time of the store the session belongs to
+func (s *session[TValue]) now() time.Time {
+	if s.store == nil {
+		return time.Now()
+	}
+
+	return s.store.now()
+}
@@ -0,0 +1,133 @@
+package sessions
+
+import (
+	"errors"
+	"net/http"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+var (
+	//ErrSameSiteNoneInsecure is returned when SameSite=None is used without Secure, which browsers reject
+	ErrSameSiteNoneInsecure = errors.New("sessions: SameSite=None cookies must be Secure")
+
+	//ErrPartitionedInsecure is returned when Partitioned is used without Secure, which browsers reject
+	ErrPartitionedInsecure = errors.New("sessions: Partitioned cookies must be Secure")
+
+	//ErrMalformedCookie is returned when the session cookie can't be parsed or its signature doesn't match
+	ErrMalformedCookie = errors.New("sessions: malformed session cookie")
+
+	//ErrMultipleCookies is returned when the request carries more than one session cookie, which is typical of cookie
+	//tossing attacks
+	ErrMultipleCookies = errors.New("sessions: multiple session cookies")
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//CookieOptions hold attributes applied to every cookie set by the SessionStore
+type CookieOptions struct {
+	Path     string        `json:"path" bson:"path"`
+	Domain   string        `json:"domain" bson:"domain"`
+	MaxAge   int           `json:"max_age" bson:"max_age"`
+	Secure   bool          `json:"secure" bson:"secure"`
+	HttpOnly bool          `json:"http_only" bson:"http_only"`
+	SameSite http.SameSite `json:"same_site" bson:"same_site"`
+
+	//Partitioned enables CHIPS, so the cookie is kept per top-level site when used in third-party contexts
+	Partitioned bool `json:"partitioned" bson:"partitioned"`
+}
+
+//Validate checks whether browsers would accept cookies with these options
+func (o *CookieOptions) Validate() error {
+	if o.SameSite == http.SameSiteNoneMode && !o.Secure {
+		return ErrSameSiteNoneInsecure
+	}
+
+	if o.Partitioned && !o.Secure {
+		return ErrPartitionedInsecure
+	}
+
+	return nil
+}
+
+//Creates a new cookie with these options applied
+func (o *CookieOptions) cookie() *http.Cookie {
+	return &http.Cookie{
+		Path:        o.Path,
+		Domain:      o.Domain,
+		MaxAge:      o.MaxAge,
+		Secure:      o.Secure,
+		HttpOnly:    o.HttpOnly,
+		SameSite:    o.SameSite,
+		Partitioned: o.Partitioned,
+	}
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//EmbeddedCookieOptions returns a preset for sessions used in third-party contexts such as iframes and embedded
+//widgets: SameSite=None, Secure, HttpOnly and Partitioned
+func EmbeddedCookieOptions() *CookieOptions {
+	return &CookieOptions{
+		Path:        "/",
+		Secure:      true,
+		HttpOnly:    true,
+		SameSite:    http.SameSiteNoneMode,
+		Partitioned: true,
+	}
+}
+
+//Reports the bad cookie to Requirements.OnBadCookie and, if Requirements.ClearBadCookies is set, expires the cookie
+//with the name supplied on the response
+func (ss *SessionStore[TValue]) badCookie(w http.ResponseWriter, name string, err error) {
+	r := ss.req()
+
+	if r.OnBadCookie != nil {
+		ss.protectReport("OnBadCookie", func() { r.OnBadCookie(err) })
+	}
+
+	if r.ClearBadCookies && w != nil {
+		ss.expireCookie(w, name)
+	}
+}
+
+//ExpireHttpCookie tells the browser to delete the session cookie, e.g. on logout
+func (ss *SessionStore[TValue]) ExpireHttpCookie(w http.ResponseWriter) {
+	ss.ready()
+
+	ss.expireCookie(w, ss.req().DefaultKey)
+}
+
+//Tells the browser to delete the cookie with the name supplied. Requirements.CookieOptions are applied, so the path
+//and domain match those the cookie was set with
+func (ss *SessionStore[TValue]) expireCookie(w http.ResponseWriter, name string) {
+	c := ss.baseCookie()
+	c.Name = name
+	c.Value = ""
+	c.MaxAge = -1
+	enforceCookieAttributes(c)
+
+	http.SetCookie(w, c)
+}
+
+//Returns the cookie session cookies are built on: Requirements.CookieOptions, or a cookie for the whole site if they
+//aren't set. Setting and deleting cookies both start from it, so a deleted cookie always matches the one set
+func (ss *SessionStore[TValue]) baseCookie() *http.Cookie {
+	if o := ss.req().CookieOptions; o != nil {
+		return o.cookie()
+	}
+
+	return defaultCookie()
+}
+
+//Returns the cookie used when there are no CookieOptions
+func defaultCookie() *http.Cookie {
+	return &http.Cookie{Path: "/"}
+}
+
+//Makes sure the cookie would not be rejected by browsers. SameSite=None and Partitioned cookies are always Secure
+func enforceCookieAttributes(c *http.Cookie) {
+	if c.SameSite == http.SameSiteNoneMode || c.Partitioned {
+		c.Secure = true
+	}
+}
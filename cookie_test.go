@@ -0,0 +1,62 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSession_SetHttpCookie_Embedded(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{CookieOptions: EmbeddedCookieOptions()})
+	s := ss.New("value").(*Session[string])
+
+	if err := ss.Requirements.CookieOptions.Validate(); err != nil {
+		t.Errorf("Expected embedded preset to be valid, got %s", err)
+	}
+
+	w := httptest.NewRecorder()
+	s.SetHttpCookie(w, &http.Cookie{SameSite: http.SameSiteNoneMode})
+
+	if c := w.Result().Cookies()[0]; !c.Secure {
+		t.Errorf("Expected SameSite=None cookie to be made Secure")
+	}
+
+	w = httptest.NewRecorder()
+	s.SetHttpCookie(w, nil)
+
+	if c := w.Result().Cookies()[0]; !c.Partitioned || c.SameSite != http.SameSiteNoneMode {
+		t.Errorf("Expected cookie options from Requirements to be applied, got %s", c)
+	}
+
+	if err := (&CookieOptions{SameSite: http.SameSiteNoneMode}).Validate(); err != ErrSameSiteNoneInsecure {
+		t.Errorf("Expected ErrSameSiteNoneInsecure, got %v", err)
+	}
+}
+
+func TestSession_CookieHeaderValue(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{CookieOptions: &CookieOptions{Path: "/", HttpOnly: true}})
+	s := ss.New("value").(*Session[string])
+
+	w := httptest.NewRecorder()
+	s.SetHttpCookie(w, nil)
+
+	if v := s.CookieHeaderValue(); v != w.Header().Get("Set-Cookie") {
+		t.Errorf("Expected header value \"%s\", got \"%s\"", w.Header().Get("Set-Cookie"), v)
+	}
+}
+
+func TestSessionStore_ExpireHttpCookie_Path(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	s := ss.New("value")
+
+	set, expired := httptest.NewRecorder(), httptest.NewRecorder()
+	s.(CookieWriter).SetHttpCookie(set, nil)
+	ss.ExpireHttpCookie(expired)
+
+	a, b := set.Result().Cookies(), expired.Result().Cookies()
+	if len(a) != 1 || len(b) != 1 || a[0].Path != "/" || a[0].Path != b[0].Path || a[0].Domain != b[0].Domain {
+		t.Errorf("Expected the cookie to be deleted with the path and domain it was set with, got %v and %v", a, b)
+	}
+}
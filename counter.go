@@ -0,0 +1,58 @@
+package sessions
+
+import "errors"
+
+//===========[INTERFACES]====================================================================================================
+
+//Number is any integer or floating point type that can be used as a counter
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Add increments the numeric value of the session by delta under the session lock and returns the new value. It's
+//meant for per-session quotas, page counters and the like. The session must implement Updater, otherwise
+//errors.ErrUnsupported is returned
+func Add[N Number](s ISession[N], delta N) (N, error) {
+	u, ok := s.(Updater[N])
+	if !ok {
+		return 0, errors.ErrUnsupported
+	}
+
+	var n N
+	err := u.UpdateE(func(v *N) {
+		*v += delta
+		n = *v
+	})
+
+	if err != nil {
+		return s.Value(), err
+	}
+
+	return n, nil
+}
+
+//AddAttr increments the numeric attribute of the session by delta under the session lock and returns the new value.
+//Missing attributes, or ones of a different type, count from 0. While the store is read-only, the attribute is left
+//as it was and its current value is returned with ErrReadOnly
+func AddAttr[N Number](s Attributer, key AttrKey[N], delta N) (N, error) {
+	var n N
+	added := false
+
+	s.UpdateAttr(key.Name(), func(v any, _ bool) any {
+		current, _ := v.(N)
+		n = current + delta
+		added = true
+		return n
+	})
+
+	if !added {
+		current, _ := key.Get(s)
+		return current, ErrReadOnly
+	}
+
+	return n, nil
+}
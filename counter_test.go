@@ -0,0 +1,39 @@
+package sessions
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestAdd(t *testing.T) {
+	ss := New[int](nil)
+	s := ss.New(0)
+	views := NewAttrKey[uint]("views")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = Add(s, 2)
+			_, _ = AddAttr(s.(Attributer), views, 1)
+		}()
+	}
+	wg.Wait()
+
+	if n, err := Add(s, -1); err != nil || n != 99 {
+		t.Errorf("Expected counter 99 after concurrent increments, got %d, %v", n, err)
+	}
+
+	if n, _ := views.Get(s.(Attributer)); n != 50 {
+		t.Errorf("Expected attribute counter 50, got %d", n)
+	}
+
+	ss.SetReadOnly(true)
+	if n, err := AddAttr(s.(Attributer), views, 1); !errors.Is(err, ErrReadOnly) || n != 50 {
+		t.Errorf("Expected the current value with ErrReadOnly while read-only, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,169 @@
+package sessions
+
+import (
+	"reflect"
+	"sort"
+)
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Default Differ. Compares exported fields of struct values, for any other kind of value it returns nil
+func structDiffer[TValue any](old, new TValue) []string {
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(new)
+
+	if !ov.IsValid() || ov.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []string
+
+	for i := 0; i < ov.NumField(); i++ {
+		f := ov.Type().Field(i)
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if !reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			fields = append(fields, f.Name)
+		}
+	}
+
+	return fields
+}
+
+//Records fields that differ between old and new values. This method is not protected by a mutex. A panic of
+//Requirements.Differ is returned, to be reported once the session is unlocked
+func (s *session[TValue]) markDirty(old, new TValue) error {
+	if s.store == nil {
+		return nil
+	}
+
+	r := s.store.req()
+	differ := r.Differ
+	if differ == nil || r.Ephemeral {
+		return nil
+	}
+
+	var fields []string
+	err := s.store.protect("Differ", func() error {
+		fields = differ(old, new)
+		return nil
+	})
+	if err != nil {
+		//Changed fields are no longer known, so the whole value is written
+		s.dirtyFields = nil
+		return err
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if s.dirtyFields == nil {
+		s.dirtyFields = make(map[string]struct{}, len(fields))
+	}
+
+	for _, f := range fields {
+		s.dirtyFields[f] = struct{}{}
+	}
+
+	return nil
+}
+
+//Adds the session to the modified ones, unless Requirements.Ephemeral is set
+func (ss *SessionStore[TValue]) markModified(key string, s *Session[TValue]) {
+	if ss.req().Ephemeral {
+		return
+	}
+
+	ss._modifiedSessions.Add(key, s)
+}
+
+//Returns sorted field names from the set supplied
+func sortedFields(set map[string]struct{}) []string {
+	fields := make([]string, 0, len(set))
+	for f := range set {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+//DirtyFields returns sorted names of the fields of Value that were changed since the last flush. Empty result means
+//that individual fields are not known and the whole value should be written
+func (s *Session[TValue]) DirtyFields() []string {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return sortedFields(s.session.dirtyFields)
+}
+
+//Flush calls the function supplied for every modified session together with the fields that changed since the last
+//flush. Sessions that were flushed successfully are no longer considered modified. The first error stops the flush and
+//is returned, leaving the rest of the sessions for the next attempt
+func (ss *SessionStore[TValue]) Flush(f func(s ISession[TValue], dirtyFields []string) error) error {
+	ss.ready()
+
+	return ss.flush(func(s *Session[TValue], dirtyFields []string) error {
+		return ss.protect("Flush", func() error { return f(s, dirtyFields) })
+	})
+}
+
+//Same as Flush, but hands over the concrete session
+func (ss *SessionStore[TValue]) flush(f func(s *Session[TValue], dirtyFields []string) error) error {
+	for key, s := range ss._modifiedSessions.GetAll() {
+		if err := ss.flushSession(key, s, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Flushes a single session. Concurrent flushes of the same session run one after another, and those that find the
+//session already written by the previous one are skipped, so every write carries state at least as new as the one
+//before it
+func (ss *SessionStore[TValue]) flushSession(key string, s *Session[TValue], f func(s *Session[TValue], dirtyFields []string) error) error {
+	s.flushMx.Lock()
+	defer s.flushMx.Unlock()
+
+	if ss._modifiedSessions.GetValue(key) != s {
+		return nil
+	}
+
+	//Dirty fields are taken before flushing, so changes made during the flush are kept for the next one
+	s.mx.Lock()
+	taken := s.session.dirtyFields
+	s.session.dirtyFields = nil
+	fields := sortedFields(taken)
+	seen := s.session.modifications
+	s.mx.Unlock()
+
+	//A session that was never stored in the Backend has no fields to patch there, so it's written as a whole
+	if s.Version() == 0 && ss.req().Backend != nil {
+		fields = nil
+	}
+
+	if err := f(s, fields); err != nil {
+		s.mx.Lock()
+		if s.session.dirtyFields == nil {
+			s.session.dirtyFields = make(map[string]struct{}, len(taken))
+		}
+		for field := range taken {
+			s.session.dirtyFields[field] = struct{}{}
+		}
+		s.mx.Unlock()
+
+		return err
+	}
+
+	//Changed during the flush, so it stays modified for the next one
+	s.mx.Lock()
+	if s.session.modifications == seen {
+		ss._modifiedSessions.Remove(key)
+	}
+	s.mx.Unlock()
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package sessions
+
+import "testing"
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_Flush(t *testing.T) {
+	type cart struct {
+		Items []string
+		Total int
+		Note  string
+	}
+
+	ss := New[cart](nil)
+	s := ss.New(cart{}).(*Session[cart])
+
+	if err := ss.Flush(func(ISession[cart], []string) error { return nil }); err != nil {
+		t.Errorf("Expected the first flush to succeed, got %s", err)
+	}
+
+	s.Update(func(c *cart) {
+		c.Items = append(c.Items, "apple")
+		c.Total = 3
+	})
+
+	var flushed []string
+	_ = ss.Flush(func(s ISession[cart], dirtyFields []string) error {
+		flushed = dirtyFields
+		return nil
+	})
+
+	if len(flushed) != 2 || flushed[0] != "Items" || flushed[1] != "Total" {
+		t.Errorf("Expected dirty fields to be [Items Total], got %v", flushed)
+	}
+
+	if len(s.DirtyFields()) != 0 {
+		t.Errorf("Expected dirty fields to be cleared after flush, got %v", s.DirtyFields())
+	}
+}
@@ -0,0 +1,76 @@
+package sessions
+
+import (
+	"errors"
+	"net/http"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//DrainReport describes the state the store was left in by Drain
+type DrainReport[TValue any] struct {
+	//Snapshot is a read-only copy of the store taken after the final flush
+	Snapshot *ReadOnlySessionStore[TValue]
+
+	//Err is the error of the final flush to the Backend, if any
+	Err error
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Drain prepares the store for shutdown: it stops issuing new sessions, writes modified sessions to the Backend, if
+//there is one, and takes a final snapshot. Existing sessions keep working, so requests still in flight can finish.
+//Tenant partitions of the store stop issuing sessions and are flushed along with it, but the snapshot only covers
+//the store itself
+func (ss *SessionStore[TValue]) Drain() DrainReport[TValue] {
+	ss.ready()
+
+	ss.mx.Lock()
+	ss.draining = true
+	tenants := make([]*SessionStore[TValue], 0, len(ss.tenants))
+	for _, t := range ss.tenants {
+		tenants = append(tenants, t)
+	}
+	ss.mx.Unlock()
+
+	var report DrainReport[TValue]
+	var errs []error
+
+	if ss.req().Backend != nil {
+		errs = append(errs, ss.FlushToBackend())
+	}
+
+	for _, t := range tenants {
+		if t.req().Backend != nil {
+			errs = append(errs, t.FlushToBackend())
+		}
+	}
+
+	report.Err = errors.Join(errs...)
+	report.Snapshot = ss.CloneReadOnly()
+
+	return report
+}
+
+//Checks whether the store, or the store the partition belongs to, has been drained
+func (ss *SessionStore[TValue]) isDraining() bool {
+	ss.mx.RLock()
+	draining := ss.draining
+	ss.mx.RUnlock()
+
+	return draining || (ss.parent != nil && ss.parent.isDraining())
+}
+
+//DrainOnShutdown ties the store to the lifecycle of the server: once srv.Shutdown is called, the store is drained
+//and the report is delivered on the returned channel
+func (ss *SessionStore[TValue]) DrainOnShutdown(srv *http.Server) <-chan DrainReport[TValue] {
+	ss.ready()
+
+	done := make(chan DrainReport[TValue], 1)
+
+	srv.RegisterOnShutdown(func() {
+		done <- ss.Drain()
+	})
+
+	return done
+}
@@ -0,0 +1,39 @@
+package sessions
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_DrainOnShutdown(t *testing.T) {
+	backend := newTestBackend()
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: backend})
+	s := ss.New("value")
+
+	srv := &http.Server{}
+	done := ss.DrainOnShutdown(srv)
+	_ = srv.Shutdown(context.Background())
+
+	var report DrainReport[string]
+	select {
+	case report = <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the store to be drained on shutdown")
+	}
+
+	if report.Err != nil || report.Snapshot.Count() != 1 {
+		t.Errorf("Expected a clean drain with 1 session in the snapshot, got %v, %d", report.Err, report.Snapshot.Count())
+	}
+
+	if _, exist := backend.records[StorageKeyOf(s)]; !exist {
+		t.Errorf("Expected modified sessions to be flushed")
+	}
+
+	if _, err := ss.NewE("late"); err != ErrDraining {
+		t.Errorf("Expected new sessions to be refused after the drain, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package sessions
+
+import "errors"
+
+//===========[CACHE/STATIC]=============================================================================================
+
+var (
+	//ErrNotFound is returned when the session does not exist
+	ErrNotFound = errors.New("sessions: session not found")
+
+	//ErrVersionConflict is returned when the session was written by someone else since it was last read
+	ErrVersionConflict = errors.New("sessions: version conflict")
+
+	//ErrInvalidRequirements is wrapped by every error returned from Requirements.Validate
+	ErrInvalidRequirements = errors.New("sessions: invalid requirements")
+
+	//ErrInvariantViolation is reported to Requirements.OnError when Requirements.Debug is set and internal caches drift
+	ErrInvariantViolation = errors.New("sessions: invariant violation")
+
+	//ErrNoBackend is returned when an operation requires Requirements.Backend, but it is not set
+	ErrNoBackend = errors.New("sessions: backend is not set")
+
+	//ErrReadOnly is returned by operations that would change sessions while the store is read-only, see SetReadOnly
+	ErrReadOnly = errors.New("sessions: store is read-only")
+
+	//ErrExists is returned by Import when a session with the UID already exists
+	ErrExists = errors.New("sessions: session already exists")
+
+	//ErrExpired is returned by Import when the session has already expired
+	ErrExpired = errors.New("sessions: session expired")
+
+	//ErrUidExhausted is returned when no free UID was generated within Requirements.MaxUidAttempts
+	ErrUidExhausted = errors.New("sessions: no free uid found")
+
+	//ErrCorrupted is returned by ChecksumBackend when a stored value doesn't match its checksum
+	ErrCorrupted = errors.New("sessions: corrupted session record")
+
+	//ErrQuotaExceeded is returned when a new session doesn't fit in Requirements.Quota or Requirements.LabelQuotas
+	ErrQuotaExceeded = errors.New("sessions: quota exceeded")
+
+	//ErrQueueFull is reported by NewAsync when Requirements.NewQueueSize sessions are already waiting to be created
+	ErrQueueFull = errors.New("sessions: new session queue is full")
+
+	//ErrInvalidTenant is returned by TenantE for tenant names containing "/", which would make storage keys of
+	//different tenants collide
+	ErrInvalidTenant = errors.New("sessions: invalid tenant name")
+
+	//ErrTenantLimit is returned by TenantE once Requirements.MaxTenants partitions exist
+	ErrTenantLimit = errors.New("sessions: too many tenants")
+
+	//ErrNotLoaded is returned when the value of a hibernated session can't be loaded back from the Backend
+	ErrNotLoaded = errors.New("sessions: hibernated session could not be loaded")
+
+	//ErrUnkeyedSessions is returned by RotateKeys when the first key would be added while the store has sessions
+	ErrUnkeyedSessions = errors.New("sessions: store has sessions issued without keys")
+
+	//ErrDraining is returned by NewE once the store has been drained, see Drain
+	ErrDraining = errors.New("sessions: store is draining")
+
+	//ErrNilStore is the panic value of methods called on a nil *SessionStore
+	ErrNilStore = errors.New("sessions: nil session store, create one with New")
+)
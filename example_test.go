@@ -0,0 +1,247 @@
+package sessions_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/emillis/sessions"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//redisBackend stands in for a Backend talking to Redis. A real one would issue GET, SET and DEL with a WATCH on the
+//key to check the version
+type redisBackend struct {
+	name     string
+	mx       sync.Mutex
+	values   map[string]string
+	versions map[string]uint64
+}
+
+func (b *redisBackend) Load(key string) (string, uint64, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	v, ok := b.values[key]
+	if !ok {
+		return "", 0, sessions.ErrNotFound
+	}
+
+	return v, b.versions[key], nil
+}
+
+func (b *redisBackend) Save(s sessions.ISession[string], dirtyFields []string, expectedVersion uint64) (uint64, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	key := sessions.StorageKeyOf(s)
+	if b.versions[key] != expectedVersion {
+		return 0, sessions.ErrVersionConflict
+	}
+
+	b.values[key] = s.Value()
+	b.versions[key]++
+
+	return b.versions[key], nil
+}
+
+func (b *redisBackend) Remove(key string) error {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	delete(b.values, key)
+	delete(b.versions, key)
+
+	return nil
+}
+
+func newRedisBackend(name string) *redisBackend {
+	return &redisBackend{name: name, values: map[string]string{}, versions: map[string]uint64{}}
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Sessions are loaded by Middleware and picked up by handlers with FromContext
+func ExampleSessionStore_Middleware() {
+	ss := sessions.New[string](nil)
+
+	login := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := ss.New("alice")
+		s.(sessions.CookieWriter).SetHttpCookie(w, nil)
+	})
+
+	profile := ss.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s := sessions.FromContext[string](r.Context()); s != nil {
+			fmt.Fprintf(w, "hello, %s", s.Value())
+			return
+		}
+
+		http.Error(w, "who are you?", http.StatusUnauthorized)
+	}))
+
+	w := httptest.NewRecorder()
+	login.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+
+	r := httptest.NewRequest(http.MethodGet, "/profile", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+
+	w = httptest.NewRecorder()
+	profile.ServeHTTP(w, r)
+	fmt.Println(w.Body.String())
+
+	w = httptest.NewRecorder()
+	profile.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/profile", nil))
+	fmt.Println(w.Code)
+
+	// Output:
+	// hello, alice
+	// 401
+}
+
+//Require guards handlers, here letting only admins through
+func ExampleSessionStore_Require() {
+	ss := sessions.New[string](nil)
+	admin, user := ss.New("admin"), ss.New("user")
+
+	isAdmin := func(s sessions.ISession[string]) bool { return s.Value() == "admin" }
+	h := ss.Require(isAdmin, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "welcome")
+	}))
+
+	for _, s := range []sessions.ISession[string]{admin, user} {
+		r := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		c, err := http.ParseSetCookie(s.(sessions.CookieWriter).CookieHeaderValue())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		r.AddCookie(c)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		fmt.Println(s.Value(), w.Code)
+	}
+
+	// Output:
+	// admin 200
+	// user 401
+}
+
+//Backends are chained, e.g. Redis in front of a slower durable store. Modified sessions reach both on
+//FlushToBackend, and removed ones are cleaned up from both
+func Example_redisBackend() {
+	redis, durable := newRedisBackend("redis"), newRedisBackend("durable")
+
+	ss := sessions.New[string](&sessions.Requirements[string]{
+		Backend: sessions.Chain[string](redis, durable),
+	})
+
+	s := ss.New("cart: 2 items")
+	if err := ss.FlushToBackend(); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, b := range []*redisBackend{redis, durable} {
+		v, version, err := b.Load(sessions.StorageKeyOf(s))
+		fmt.Println(b.name, v, version, err)
+	}
+
+	ss.Remove(s.Uid())
+
+	for _, b := range []*redisBackend{redis, durable} {
+		_, _, err := b.Load(sessions.StorageKeyOf(s))
+		fmt.Println(b.name, err == sessions.ErrNotFound)
+	}
+
+	// Output:
+	// redis cart: 2 items 1 <nil>
+	// durable cart: 2 items 1 <nil>
+	// redis true
+	// durable true
+}
+
+//A CSRF token is kept as a typed attribute next to the value of the session and checked on every form submission
+func Example_csrf() {
+	ss := sessions.New[string](nil)
+	csrf := sessions.NewAttrKey[string]("csrf")
+
+	s := ss.New("alice").(sessions.Attributer)
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		fmt.Println(err)
+		return
+	}
+	csrf.Set(s, hex.EncodeToString(b))
+
+	valid := func(s sessions.Attributer, submitted string) bool {
+		token, ok := csrf.Get(s)
+		return ok && token == submitted
+	}
+
+	token, _ := csrf.Get(s)
+	fmt.Println(valid(s, token))
+	fmt.Println(valid(s, "forged"))
+
+	// Output:
+	// true
+	// false
+}
+
+//API tokens carry scopes that are checked when the token is presented
+func ExampleSessionStore_NewToken() {
+	ss := sessions.New[string](nil)
+
+	token, err := ss.NewToken("ci-bot", []string{"read"}, time.Hour)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	s, err := ss.ValidateToken(token, "read")
+	fmt.Println(s.Value(), err)
+
+	_, err = ss.ValidateToken(token, "write")
+	fmt.Println(err == sessions.ErrInsufficientScope)
+
+	// Output:
+	// ci-bot <nil>
+	// true
+}
+
+//Tenants share the Backend of the store, but not their sessions
+func ExampleSessionStore_Tenant() {
+	ss := sessions.New[string](nil)
+	acme, globex := ss.Tenant("acme"), ss.Tenant("globex")
+
+	s := acme.New("wile")
+
+	fmt.Println(acme.Exist(s.Uid()), globex.Exist(s.Uid()))
+	fmt.Println(ss.Tenants())
+
+	// Output:
+	// true false
+	// [acme globex]
+}
+
+//Diff tells what changed between two snapshots of the store
+func ExampleDiff() {
+	ss := sessions.New[string](nil)
+	s := ss.New("before")
+
+	a := ss.Snapshot()
+	s.SetValue("after")
+	b := ss.Snapshot()
+
+	fmt.Println(sessions.Diff(a, b).Changed[s.Uid()])
+
+	// Output:
+	// [Value LastModified]
+}
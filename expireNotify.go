@@ -0,0 +1,95 @@
+package sessions
+
+import (
+	"sync"
+	"time"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//Expired sessions waiting to be delivered to Requirements.OnExpire
+type expireQueue[TValue any] struct {
+	pending []*Session[TValue]
+
+	//Whether the delivering goroutine is running
+	running bool
+
+	mx sync.Mutex
+}
+
+//Returns the number of sessions waiting to be delivered
+func (q *expireQueue[TValue]) depth() int {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+	return len(q.pending)
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Called by the caches with every session removed by timeout
+func (ss *SessionStore[TValue]) sessionExpired(key string, s *Session[TValue]) {
+	//Otherwise the next flush would write the expired session back to the Backend
+	if ss._modifiedSessions.GetValue(key) == s {
+		ss._modifiedSessions.Remove(key)
+	}
+
+	if l, ok := ss.req().Backend.(ExpiryListener); ok {
+		l.SessionExpired(key)
+	}
+
+	ss.stats.removed(s.Label())
+	ss.index.remove(s)
+	s.releaseQuota()
+	s.notify(ChangeExpired)
+	ss.endFamily(s, true)
+
+	if ss.req().OnExpire == nil {
+		return
+	}
+
+	q := &ss.expireQueue
+
+	q.mx.Lock()
+	q.pending = append(q.pending, s)
+	start := !q.running
+	q.running = true
+	q.mx.Unlock()
+
+	if start {
+		go ss.deliverExpired()
+	}
+}
+
+//Passes queued sessions to Requirements.OnExpire in batches, waiting Requirements.OnExpireInterval between calls, until
+//the queue is empty
+func (ss *SessionStore[TValue]) deliverExpired() {
+	q := &ss.expireQueue
+
+	for {
+		r := ss.req()
+
+		q.mx.Lock()
+		if len(q.pending) == 0 {
+			q.pending = nil
+			q.running = false
+			q.mx.Unlock()
+			return
+		}
+
+		n := min(len(q.pending), r.OnExpireBatchSize)
+		batch := make([]ISession[TValue], n)
+		for i, s := range q.pending[:n] {
+			batch[i] = s
+		}
+		q.pending = q.pending[n:]
+		q.mx.Unlock()
+
+		if r.OnExpire != nil {
+			ss.protectReport("OnExpire", func() { r.OnExpire(batch) })
+		}
+
+		if r.OnExpireInterval > 0 {
+			time.Sleep(r.OnExpireInterval)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestRequirements_OnExpire(t *testing.T) {
+	batches := make(chan int)
+	release := make(chan struct{})
+
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Timeout:           10 * time.Millisecond,
+		OnExpireBatchSize: 2,
+		OnExpireInterval:  time.Millisecond,
+		OnExpire: func(expired []ISession[string]) {
+			batches <- len(expired)
+			<-release
+		},
+	})
+
+	for i := 0; i < 5; i++ {
+		ss.New("value")
+	}
+
+	//Every session expires here, the first batch is then held by OnExpire until released
+	clockOf(ss).Advance(10 * time.Millisecond)
+	first := <-batches
+
+	if depth := ss.Stats().ExpireQueue; first+depth != 5 {
+		t.Errorf("Expected %d sessions to be queued while OnExpire is busy, got %d", 5-first, depth)
+	}
+
+	total := first
+	close(release)
+	for total < 5 {
+		n := <-batches
+		if n > 2 {
+			t.Errorf("Expected batches of at most 2 sessions, got %d", n)
+		}
+		total += n
+	}
+}
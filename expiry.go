@@ -0,0 +1,71 @@
+package sessions
+
+import "sync"
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Restarts the expiry timer of the session in the store. Absolute deadline takes precedence over Requirements.TTL
+func (ss *SessionStore[TValue]) armTimer(s *Session[TValue]) {
+	s.mx.RLock()
+	uid, expiresAt, suspended := s.session.Uid, s.session.ExpiresAt, s.session.suspensions > 0
+	createdAt, lastModified := s.session.CreatedAt, s.session.LastModified
+	s.mx.RUnlock()
+
+	key := ss.storageKey(uid)
+
+	if !expiresAt.IsZero() {
+		ss._sessions.AddTimer(key, expiresAt.Sub(ss.now()))
+		return
+	}
+
+	if suspended {
+		return
+	}
+
+	if timeout, ok := ss.timeLeft(createdAt, lastModified); ok {
+		ss._sessions.AddTimer(key, timeout)
+	}
+}
+
+//SuspendExpiry prevents the session from timing out, e.g. during a large upload or report generation, until the
+//returned release function is called. Releasing restarts the idle clock. Suspensions can be nested, in which case the
+//session times out again only when every one of them is released. Each suspension is released automatically after
+//Requirements.MaxExpirySuspension. Absolute deadlines set by ExpireAt are not suspended
+func (s *Session[TValue]) SuspendExpiry() func() {
+	if s.store == nil {
+		return func() {}
+	}
+
+	s.mx.Lock()
+	s.session.suspensions++
+	uid, hasDeadline := s.session.Uid, !s.session.ExpiresAt.IsZero()
+	s.mx.Unlock()
+
+	if !hasDeadline {
+		s.store._sessions.StopTimer(s.store.storageKey(uid))
+	}
+
+	//The suspension ends either way, but the idle clock is only restarted while the store is writable
+	resume := func() {
+		writable := s.writable() == nil
+
+		s.mx.Lock()
+		s.session.suspensions--
+		if writable {
+			s.session.updateLastModified()
+		}
+		s.mx.Unlock()
+
+		s.store.armTimer(s)
+	}
+
+	var once sync.Once
+	capTimer := s.store.clock.AfterFunc(s.store.req().MaxExpirySuspension, func() { once.Do(resume) })
+
+	return func() {
+		once.Do(func() {
+			capTimer.Stop()
+			resume()
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSession_ExpireAt(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{Timeout: time.Hour})
+	s := ss.New("value").(*Session[string])
+
+	clk := clockOf(ss)
+	s.ExpireAt(clk.Now().Add(20 * time.Millisecond))
+
+	if ss.Get(s.Uid()) == nil {
+		t.Errorf("Session with UID \"%s\" shouldn't expire before the deadline", s.Uid())
+	}
+
+	clk.Advance(20 * time.Millisecond)
+
+	if ss.Get(s.Uid()) != nil {
+		t.Errorf("Session with UID \"%s\" should have expired at the deadline", s.Uid())
+	}
+
+	past := ss.New("past").(*Session[string])
+	past.ExpireAt(clk.Now().Add(-time.Second))
+
+	if ss.Exist(past.Uid()) {
+		t.Errorf("Session with a deadline in the past should be removed immediately")
+	}
+}
+
+func TestSession_SuspendExpiry(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{Timeout: 30 * time.Millisecond})
+	s := ss.New("value").(*Session[string])
+
+	clk := clockOf(ss)
+	release := s.SuspendExpiry()
+	clk.Advance(60 * time.Millisecond)
+
+	if !ss.Exist(s.Uid()) {
+		t.Fatalf("Session with UID \"%s\" shouldn't time out while expiry is suspended", s.Uid())
+	}
+
+	release()
+	clk.Advance(30 * time.Millisecond)
+
+	if ss.Exist(s.Uid()) {
+		t.Errorf("Session with UID \"%s\" should time out after expiry is resumed", s.Uid())
+	}
+}
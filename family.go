@@ -0,0 +1,144 @@
+package sessions
+
+import "slices"
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//NewChild creates a session with the value that is bound to the lifetime of this one, e.g. a scoped task session
+//spawned by a device session or a session delegated to a sub-client. Removing this session, or it being evicted,
+//removes its children as well, and expiring it expires them. The same goes for their own children. Otherwise children
+//are sessions of their own: they have their own UID, value and timeout, and removing a child leaves the parent alone.
+//The child gets the label of the parent. Links are kept in memory and carried over by handoffs, but they are not
+//stored in the Backend. Returns ErrNotFound if this session is no longer in a store
+func (s *Session[TValue]) NewChild(data TValue) (ISession[TValue], error) {
+	ss := s.store
+	if ss == nil {
+		return nil, ErrNotFound
+	}
+
+	s.mx.RLock()
+	ended, label := s.session.ended, s.session.label
+	s.mx.RUnlock()
+
+	if ended {
+		return nil, ErrNotFound
+	}
+
+	created, err := ss.newValidated(data, label)
+	if err != nil {
+		return nil, err
+	}
+
+	//Interceptors may have wrapped the session
+	child := ss._sessions.GetValue(ss.storageKey(created.Uid()))
+	if child == nil {
+		return nil, ErrNotFound
+	}
+
+	if !ss.link(s, child) {
+		ss.remove(child.Uid())
+		return nil, ErrNotFound
+	}
+
+	return created, nil
+}
+
+//Parent returns the session this one was created by with NewChild, or nil if there is none
+func (s *Session[TValue]) Parent() ISession[TValue] {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	if s.session.parent == nil {
+		return nil
+	}
+
+	return s.session.parent
+}
+
+//Children returns the sessions created by NewChild of this session that are still in the store, oldest first
+func (s *Session[TValue]) Children() []ISession[TValue] {
+	s.mx.RLock()
+	children := make([]*Session[TValue], 0, len(s.session.children))
+	for child := range s.session.children {
+		children = append(children, child)
+	}
+	s.mx.RUnlock()
+
+	slices.SortFunc(children, func(a, b *Session[TValue]) int {
+		return a.CreatedAt().Compare(b.CreatedAt())
+	})
+
+	result := make([]ISession[TValue], len(children))
+	for i, child := range children {
+		result[i] = child
+	}
+
+	return result
+}
+
+//Links the child to the parent. Returns false if the parent has left the store in the meantime
+func (ss *SessionStore[TValue]) link(parent, child *Session[TValue]) bool {
+	child.mx.Lock()
+	child.session.parent = parent
+	child.mx.Unlock()
+
+	parent.mx.Lock()
+	defer parent.mx.Unlock()
+
+	if parent.session.ended {
+		return false
+	}
+
+	if parent.session.children == nil {
+		parent.session.children = make(map[*Session[TValue]]struct{})
+	}
+	parent.session.children[child] = struct{}{}
+
+	return true
+}
+
+//Links sessions received in a handoff to their parents, given by UID of the child. Children whose parent wasn't
+//loaded are left on their own
+func (ss *SessionStore[TValue]) relink(parents map[string]string) {
+	for uid, parentUid := range parents {
+		child := ss._sessions.GetValue(ss.storageKey(uid))
+		parent := ss._sessions.GetValue(ss.storageKey(parentUid))
+
+		if child != nil && parent != nil {
+			ss.link(parent, child)
+		}
+	}
+}
+
+//Called with every session that leaves the store. Unlinks it from its parent and makes its children leave the same
+//way, either removed or expired
+func (ss *SessionStore[TValue]) endFamily(s *Session[TValue], expired bool) {
+	s.mx.Lock()
+	s.session.ended = true
+	parent, children := s.session.parent, s.session.children
+	s.session.parent, s.session.children = nil, nil
+	s.mx.Unlock()
+
+	if parent != nil {
+		parent.mx.Lock()
+		delete(parent.session.children, s)
+		parent.mx.Unlock()
+	}
+
+	for child := range children {
+		if !expired {
+			ss.remove(child.Uid())
+			continue
+		}
+
+		//The deadline keeps the child from being touched back to life before the timer fires. Going through the
+		//timers, it expires exactly once, as if its own timeout ran out
+		child.mx.Lock()
+		child.session.ExpiresAt = ss.now()
+		child.mx.Unlock()
+
+		key := child.StorageKey()
+		ss._sessions.AddTimer(key, 0)
+		ss._hibernated.AddTimer(key, 0)
+	}
+}
@@ -0,0 +1,98 @@
+package sessions
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSession_NewChild(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+
+	device := ss.New("device").(*Session[string])
+	task, err := device.NewChild("task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := task.(Spawner[string]).NewChild("sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clockOf(ss).Advance(time.Millisecond)
+	other, _ := device.NewChild("other")
+
+	if p := sub.(Spawner[string]).Parent(); p == nil || p.Uid() != task.Uid() {
+		t.Errorf("Expected the task to be the parent of the sub task")
+	}
+	if device.Parent() != nil {
+		t.Errorf("Expected no parent of the device session")
+	}
+	if children := device.Children(); len(children) != 2 || children[0].Uid() != task.Uid() {
+		t.Errorf("Expected the device session to have 2 children, the task first, got %d", len(children))
+	}
+
+	//Removing a child leaves the parent alone
+	ss.Remove(other.Uid())
+	if !ss.Exist(device.Uid()) || len(device.Children()) != 1 {
+		t.Errorf("Expected the removed child to be unlinked from its parent")
+	}
+
+	//Regenerating keeps the links
+	device.Regenerate()
+	ss.Remove(device.Uid())
+
+	for _, s := range []ISession[string]{task, sub} {
+		if ss.Exist(s.Uid()) {
+			t.Errorf("Expected \"%s\" to be removed along with the device session", s.Value())
+		}
+	}
+
+	if _, err = device.NewChild("late"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a removed parent, got %v", err)
+	}
+
+	expiredValues := make(chan string, 2)
+	ss = initializeSessionStore(0, &Requirements[string]{Timeout: time.Hour, OnExpire: func(batch []ISession[string]) {
+		for _, s := range batch {
+			expiredValues <- s.Value()
+		}
+	}})
+
+	device = ss.New("device").(*Session[string])
+	task, _ = device.NewChild("task")
+	task.(*Session[string]).SuspendExpiry()
+	device.ExpireAt(clockOf(ss).Now().Add(time.Millisecond))
+	clockOf(ss).Advance(time.Millisecond)
+
+	if ss.Exist(task.Uid()) {
+		t.Fatalf("Expected the task to expire along with the device session")
+	}
+
+	expired := []string{<-expiredValues, <-expiredValues}
+	slices.Sort(expired)
+	if !slices.Equal(expired, []string{"device", "task"}) {
+		t.Errorf("Expected both sessions to be passed to OnExpire, got %v", expired)
+	}
+
+	var buf bytes.Buffer
+	old := initializeSessionStore(0, nil)
+	device = old.New("device").(*Session[string])
+	task, _ = device.NewChild("task")
+	if _, err = old.WriteHandoff(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := initializeSessionStore(0, nil)
+	if _, err = restored.ReadHandoff(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	restored.Remove(device.Uid())
+	if restored.Exist(task.Uid()) {
+		t.Errorf("Expected the link to be handed over")
+	}
+}
@@ -0,0 +1,55 @@
+package fasthttpstore
+
+import (
+	"github.com/emillis/sessions"
+	"github.com/valyala/fasthttp"
+)
+
+//===========[STRUCTURES]===============================================================================================
+
+//Store adapts SessionStore to fasthttp, whose request and response types have nothing in common with net/http
+type Store[TValue any] struct {
+	*sessions.SessionStore[TValue]
+}
+
+//GetFromRequestCtx returns session if UID was specified in the request cookies
+func (s *Store[TValue]) GetFromRequestCtx(ctx *fasthttp.RequestCtx) sessions.ISession[TValue] {
+	if ctx == nil {
+		return nil
+	}
+
+	value := ctx.Request.Header.Cookie(s.CurrentRequirements().DefaultKey)
+	if len(value) == 0 {
+		return nil
+	}
+
+	uid, ok := s.ParseCookieValue(string(value))
+	if !ok {
+		return nil
+	}
+
+	return s.Get(uid)
+}
+
+//SetCookie sets cookie for the session in the response. Requirements.CookieOptions of the store are applied
+func (s *Store[TValue]) SetCookie(ctx *fasthttp.RequestCtx, session sessions.ISession[TValue]) {
+	if ctx == nil {
+		return
+	}
+
+	c, ok := session.(sessions.CookieWriter)
+	if !ok {
+		return
+	}
+
+	if v := c.CookieHeaderValue(); v != "" {
+		ctx.Response.Header.Add(fasthttp.HeaderSetCookie, v)
+	}
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//New wraps the SessionStore supplied into fasthttp adapter
+func New[TValue any](ss *sessions.SessionStore[TValue]) *Store[TValue] {
+	return &Store[TValue]{ss}
+}
@@ -0,0 +1,38 @@
+package fasthttpstore
+
+import (
+	"github.com/emillis/sessions"
+	"github.com/valyala/fasthttp"
+	"strings"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestStore_GetFromRequestCtx(t *testing.T) {
+	store := New(sessions.New[string](nil))
+	s := store.New("value")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetCookie(s.Key(), s.Uid())
+
+	if got := store.GetFromRequestCtx(ctx); got == nil || got.Value() != "value" {
+		t.Errorf("Expected to receive the session from the request cookie, got %v", got)
+	}
+
+	if got := store.GetFromRequestCtx(&fasthttp.RequestCtx{}); got != nil {
+		t.Errorf("Expected nil for a request without cookie, got %v", got)
+	}
+}
+
+func TestStore_SetCookie(t *testing.T) {
+	store := New(sessions.New[string](nil))
+	s := store.New("value")
+
+	ctx := &fasthttp.RequestCtx{}
+	store.SetCookie(ctx, s)
+
+	if v := string(ctx.Response.Header.Peek(fasthttp.HeaderSetCookie)); !strings.Contains(v, s.Uid()) {
+		t.Errorf("Expected Set-Cookie header to contain the session UID, got \"%s\"", v)
+	}
+}
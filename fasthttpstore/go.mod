@@ -0,0 +1,20 @@
+module github.com/emillis/sessions/fasthttpstore
+
+go 1.25.0
+
+replace github.com/emillis/sessions => ../
+
+require (
+	github.com/emillis/sessions v0.0.0-00010101000000-000000000000
+	github.com/valyala/fasthttp v1.74.0
+)
+
+require (
+	github.com/emillis/cacheMachine v0.3.4 // indirect
+	github.com/emillis/idGen v0.2.0 // indirect
+	github.com/klauspost/compress v1.20.0 // indirect
+	github.com/molecule-man/go-brrr v1.0.1 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	golang.org/x/text v0.41.0 // indirect
+	golang.org/x/time v0.12.0 // indirect
+)
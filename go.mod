@@ -1,8 +1,10 @@
 module github.com/emillis/sessions
 
-go 1.18
+go 1.23.0
 
 require (
 	github.com/emillis/cacheMachine v0.3.4
 	github.com/emillis/idGen v0.2.0
+	golang.org/x/text v0.28.0
+	golang.org/x/time v0.12.0
 )
@@ -0,0 +1,22 @@
+package sessions
+
+import "time"
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//Idle timeout of sessions created with GuestRequirements
+const guestTimeout = 15 * time.Minute
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//GuestRequirements returns a preset for high-churn anonymous sessions: they time out after 15 minutes of inactivity,
+//are never persisted and are not tracked as modified. Adjust the result before passing it to New if needed
+func GuestRequirements[TValue any]() *Requirements[TValue] {
+	r := defaultRequirements[TValue]()
+
+	r.DefaultKey = "_guest"
+	r.Timeout = guestTimeout
+	r.Ephemeral = true
+
+	return &r
+}
@@ -0,0 +1,28 @@
+package sessions
+
+import (
+	"errors"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestGuestRequirements(t *testing.T) {
+	ss := New[string](GuestRequirements[string]())
+	s := ss.New("guest").(*Session[string])
+	s.UpdateLastModified()
+
+	if ss.Stats().Modified != 0 {
+		t.Errorf("Expected guest sessions not to be tracked as modified")
+	}
+
+	if ss.CurrentRequirements().Timeout != guestTimeout {
+		t.Errorf("Expected guest sessions to time out after %s", guestTimeout)
+	}
+
+	r := GuestRequirements[string]()
+	r.Backend = newTestBackend()
+	if err := r.Validate(); !errors.Is(err, ErrInvalidRequirements) {
+		t.Errorf("Expected guest sessions with a Backend to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+)
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//WriteHandoff drains the store, see Drain, and streams its sessions to w as SessionRecord JSON, one per line, to be
+//loaded by ReadHandoff in another process. The stream carries raw UIDs, so it must never leave the machine. Hibernated
+//sessions are loaded back from the Backend to be handed over as well. Blobs attached to the sessions go along with
+//them, so this store no longer deletes them. Returns the number of sessions written
+func (ss *SessionStore[TValue]) WriteHandoff(w io.Writer) (int, error) {
+	ss.ready()
+
+	report := ss.Drain()
+	if report.Err != nil {
+		return 0, report.Err
+	}
+
+	for key := range ss._hibernated.GetAll() {
+		if ss.wake(key) == nil && ss._hibernated.Exist(key) {
+			return 0, ErrNotLoaded
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	n := 0
+
+	for key, s := range ss._sessions.GetAll() {
+		rec := ss.ToRecord(s)
+		if err := enc.Encode(&rec); err != nil {
+			return n, err
+		}
+		n++
+
+		s.mx.Lock()
+		s.session.blobs = nil
+		s.mx.Unlock()
+		ss._blobOwners.Remove(key)
+	}
+
+	return n, nil
+}
+
+//ReadHandoff loads sessions streamed by WriteHandoff, keeping their UIDs, versions, times, remaining lifetime,
+//attributes, blobs and links to their parents. Sessions that have expired meanwhile, whose UID is already in the
+//store or that don't fit the quotas are skipped. Requirements.UidChecker is not consulted, as the sessions handed over are usually in the Backend
+//already. Returns the number of sessions loaded
+func (ss *SessionStore[TValue]) ReadHandoff(r io.Reader) (int, error) {
+	ss.ready()
+
+	if err := ss.writable(); err != nil {
+		return 0, err
+	}
+
+	dec := json.NewDecoder(r)
+	n := 0
+
+	//Parents may come after their children, so children are linked once every session is loaded
+	parents := make(map[string]string)
+
+	for {
+		var rec SessionRecord[TValue]
+		if err := dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				ss.relink(parents)
+				return n, nil
+			}
+			return n, err
+		}
+
+		if rec.Uid == "" {
+			continue
+		}
+
+		if _, err := ss.addRecord(rec, false); err != nil {
+			if errors.Is(err, ErrExists) || errors.Is(err, ErrExpired) || errors.Is(err, ErrQuotaExceeded) {
+				continue
+			}
+			ss.relink(parents)
+			return n, err
+		}
+		n++
+
+		if rec.Metadata.Parent != "" {
+			parents[rec.Uid] = rec.Metadata.Parent
+		}
+	}
+}
+
+//ServeHandoff waits for the new process to connect to l, e.g. a unix socket listener, possibly passed in by systemd
+//socket activation, and hands the sessions over to it with WriteHandoff. It returns after a single handoff, closing
+//the connection, but not the listener. Stop taking traffic before calling it, as changes made afterwards are not
+//carried over
+func (ss *SessionStore[TValue]) ServeHandoff(l net.Listener) (int, error) {
+	ss.ready()
+
+	conn, err := l.Accept()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	return ss.WriteHandoff(conn)
+}
+
+//ReceiveHandoff connects to the unix socket the old process serves ServeHandoff on and loads its sessions with
+//ReadHandoff
+func (ss *SessionStore[TValue]) ReceiveHandoff(ctx context.Context, path string) (int, error) {
+	ss.ready()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "unix", path)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			return 0, err
+		}
+	}
+
+	return ss.ReadHandoff(conn)
+}
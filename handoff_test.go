@@ -0,0 +1,93 @@
+package sessions
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_Handoff(t *testing.T) {
+	old := initializeSessionStore(0, &Requirements[string]{Timeout: time.Hour})
+	s := old.New("value")
+	s.(*Session[string]).ExpireAt(time.Now().Add(time.Minute))
+
+	l, err := net.Listen("unix", filepath.Join(t.TempDir(), "handoff.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	served := make(chan int, 1)
+	go func() {
+		n, err := old.ServeHandoff(l)
+		if err != nil {
+			t.Error(err)
+		}
+		served <- n
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	ss := initializeSessionStore(0, &Requirements[string]{Timeout: time.Hour})
+	n, err := ss.ReceiveHandoff(ctx, l.Addr().String())
+	if err != nil || n != 1 || <-served != 1 {
+		t.Fatalf("Expected one session to be handed over, got %d, %v", n, err)
+	}
+
+	got := ss.Get(s.Uid())
+	if got == nil || got.Value() != "value" || !got.(*Session[string]).ExpiresAt().Equal(s.(*Session[string]).ExpiresAt()) {
+		t.Errorf("Expected the session to keep its UID, value and expiry")
+	}
+
+	if _, err = old.NewE("value"); err != ErrDraining {
+		t.Errorf("Expected the old store to stop issuing sessions, got %v", err)
+	}
+}
+
+func TestSessionStore_HandoffRecord(t *testing.T) {
+	backend := newTestBackend()
+	taken := UidCheckerFunc(func(context.Context, string) (bool, error) { return true, nil })
+
+	old := initializeSessionStore(0, &Requirements[string]{Backend: backend, HibernateAfter: time.Hour})
+	s := old.New("value").(*Session[string])
+	NewAttrKey[string]("theme").Set(s, "dark")
+
+	idle := old.New("idle").(*Session[string])
+	idle.session.LastModified = old.now().Add(-2 * time.Hour)
+	if err := old.Hibernate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if n, err := old.WriteHandoff(&buf); err != nil || n != 2 {
+		t.Fatalf("Expected both sessions, including the hibernated one, to be written, got %d, %v", n, err)
+	}
+
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: backend, UidChecker: taken})
+	if n, err := ss.ReadHandoff(&buf); err != nil || n != 2 {
+		t.Fatalf("Expected both sessions to be loaded regardless of UidChecker, got %d, %v", n, err)
+	}
+
+	got := ss.Get(s.Uid()).(*Session[string])
+	if theme, _ := NewAttrKey[string]("theme").Get(got); theme != "dark" || got.Version() != s.Version() {
+		t.Errorf("Expected the session to keep its attributes and version, got %q, %d", theme, got.Version())
+	}
+
+	if woken := ss.Get(idle.Uid()); woken == nil || woken.Value() != "idle" {
+		t.Errorf("Expected the hibernated session to be handed over with its value")
+	}
+
+	got.SetValue("changed")
+	if err := ss.FlushToBackend(); err != nil {
+		t.Errorf("Expected the session handed over to be saved over its stored version, got %s", err)
+	}
+	if ss.Get(s.Uid()) == nil {
+		t.Errorf("Expected the session to keep its UID after the flush")
+	}
+}
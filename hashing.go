@@ -0,0 +1,306 @@
+package sessions
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//Keys shorter than this are rejected by Requirements.Validate
+const minKeyLength = 32
+
+//Number of bytes of the hash RoutingKey is made of
+const routingKeyLength = 8
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//SHA256UidHasher hashes the UID with SHA-256. It can be used as Requirements.HashUid
+func SHA256UidHasher(uid string) string {
+	sum := sha256.Sum256([]byte(uid))
+	return hex.EncodeToString(sum[:])
+}
+
+//RoutingKey derives a stable, non-secret key from the UID, e.g. for load balancers to pin the session to a node. It's a
+//short SHA-256 prefix, so it can be logged and shared without giving the UID away. It changes when the UID does
+func RoutingKey(uid string) string {
+	sum := sha256.Sum256([]byte("routing|" + uid))
+	return hex.EncodeToString(sum[:routingKeyLength])
+}
+
+//Returns HMAC-SHA256 of the data. The secret is first turned into a purpose specific key, so the same secret can be
+//used for hashing storage keys and signing cookies without one giving away the other
+func keyedHash(secret []byte, purpose, data string) []byte {
+	k := hmac.New(sha256.New, secret)
+	k.Write([]byte(purpose))
+
+	m := hmac.New(sha256.New, k.Sum(nil))
+	m.Write([]byte(data))
+
+	return m.Sum(nil)
+}
+
+//Returns the uid as normalized by Requirements.NormalizeUid
+func normalizeUidWith[TValue any](r *Requirements[TValue], uid string) string {
+	if r.NormalizeUid == nil {
+		return uid
+	}
+
+	return r.NormalizeUid(uid)
+}
+
+//Returns the uid as normalized by Requirements.NormalizeUid
+func (ss *SessionStore[TValue]) normalizeUid(uid string) string {
+	r := ss.req()
+	return normalizeUidWith(&r, uid)
+}
+
+//Returns the key the uid is stored under, using Requirements.Keys[keyIndex] if keys are in use
+func storageKeyWith[TValue any](r *Requirements[TValue], uid string, keyIndex int) string {
+	uid = normalizeUidWith(r, uid)
+
+	if r.HashUid != nil {
+		return r.HashUid(uid)
+	}
+
+	if len(r.Keys) > keyIndex {
+		return hex.EncodeToString(keyedHash(r.Keys[keyIndex], "storage", uid))
+	}
+
+	return uid
+}
+
+//Returns the key the uid is stored under
+func (ss *SessionStore[TValue]) storageKey(uid string) string {
+	r := ss.req()
+	return storageKeyWith(&r, uid, 0)
+}
+
+//Returns the keys the uid was stored under before the keys were rotated, newest first
+func (ss *SessionStore[TValue]) previousStorageKeys(uid string) []string {
+	r := ss.req()
+	if r.HashUid != nil || len(r.Keys) < 2 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(r.Keys)-1)
+	for i := 1; i < len(r.Keys); i++ {
+		keys = append(keys, storageKeyWith(&r, uid, i))
+	}
+
+	return keys
+}
+
+//Returns the key the session is cached under. It's the one of a previous key as long as the session wasn't moved
+//under the primary one yet. Returns false if the session isn't in the store
+func (ss *SessionStore[TValue]) cachedKey(s *Session[TValue]) (string, bool) {
+	uid := s.Uid()
+
+	for _, key := range append([]string{ss.storageKey(uid)}, ss.previousStorageKeys(uid)...) {
+		if ss._sessions.GetValue(key) == s || ss._hibernated.GetValue(key) == s {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+//Returns the key the session with the uid is cached under, trying the previous keys after the primary one. Returns
+//false if there is no such session
+func (ss *SessionStore[TValue]) lookupKey(uid string) (string, bool) {
+	for _, key := range append([]string{ss.storageKey(uid)}, ss.previousStorageKeys(uid)...) {
+		if ss._sessions.Exist(key) || ss._hibernated.Exist(key) {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+//Moves sessions cached under keys that were dropped from Requirements.Keys to the primary key, as they can't be
+//found under any of the remaining ones. Hibernated sessions are woken up for that, and expire if they can't be
+func (ss *SessionStore[TValue]) rekeyDropped() {
+	for old, s := range ss._sessions.GetAll() {
+		if _, ok := ss.cachedKey(s); !ok {
+			ss.move(s, old)
+			ss.removeFromBackend(old)
+		}
+	}
+
+	for old, s := range ss._hibernated.GetAll() {
+		if _, ok := ss.cachedKey(s); ok {
+			continue
+		}
+
+		if ss.wake(old) == nil {
+			ss._hibernated.AddTimer(old, 0)
+			continue
+		}
+
+		ss.move(s, old)
+		ss.removeFromBackend(old)
+	}
+}
+
+//Returns the value of the cookie for the uid. If Requirements.Keys are set, it's signed with the primary key
+func (ss *SessionStore[TValue]) cookieValue(uid string) string {
+	keys := ss.req().Keys
+	if len(keys) == 0 {
+		return uid
+	}
+
+	return uid + "." + base64.RawURLEncoding.EncodeToString(keyedHash(keys[0], "cookie", uid))
+}
+
+//Verifies the cookie value, returning the uid and whether it was signed with a key other than the primary one
+func (ss *SessionStore[TValue]) parseCookieValue(value string) (uid string, stale bool, ok bool) {
+	r := ss.req()
+	keys := r.Keys
+	if len(keys) == 0 {
+		value = normalizeUidWith(&r, value)
+		return value, false, value != "" && ss.acceptUid(&r, value)
+	}
+
+	i := strings.LastIndexByte(value, '.')
+	if i < 1 {
+		return "", false, false
+	}
+
+	//The signature is compared in its encoded form, as decoding would accept several spellings of the same signature,
+	//e.g. with stray trailing bits or line breaks
+	sig := []byte(value[i+1:])
+	uid = normalizeUidWith(&r, value[:i])
+	if !ss.acceptUid(&r, uid) {
+		return "", false, false
+	}
+
+	for n, key := range keys {
+		if hmac.Equal(sig, []byte(base64.RawURLEncoding.EncodeToString(keyedHash(key, "cookie", uid)))) {
+			return uid, n > 0, true
+		}
+	}
+
+	return "", false, false
+}
+
+//ParseCookieValue verifies the value of a session cookie and returns the UID it holds. Without Requirements.Keys the
+//value is the UID itself
+func (ss *SessionStore[TValue]) ParseCookieValue(value string) (string, bool) {
+	ss.ready()
+
+	uid, _, ok := ss.parseCookieValue(value)
+	return uid, ok
+}
+
+//Moves the session from the old storage key to the one its current UID maps to. The record under the old key is left
+//in the Backend
+func (ss *SessionStore[TValue]) move(s *Session[TValue], old string) {
+	key := s.StorageKey()
+
+	ss._sessions.Remove(old)
+	ss._modifiedSessions.Remove(old)
+	ss._sessions.Add(key, s)
+	ss.armTimer(s)
+
+	if ss._blobOwners.Exist(old) {
+		ss._blobOwners.Remove(old)
+		ss._blobOwners.Add(key, s)
+	}
+
+	//The record under the new key doesn't exist in the Backend yet
+	s.mx.Lock()
+	s.session.version = 0
+	s.touch()
+	s.mx.Unlock()
+}
+
+//Gives the session the UID and moves it under the matching storage key
+func (ss *SessionStore[TValue]) setUid(s *Session[TValue], uid string) {
+	old := s.StorageKey()
+	uid = ss.normalizeUid(uid)
+
+	s.mx.Lock()
+	s.session.Uid = uid
+	s.mx.Unlock()
+
+	ss.move(s, old)
+}
+
+//Gives the session a newly generated UID and moves it under the matching storage key
+func (ss *SessionStore[TValue]) reassignUid(s *Session[TValue]) error {
+	uid, err := generateUid(ss)
+	if err != nil {
+		return err
+	}
+
+	ss.setUid(s, uid)
+
+	return nil
+}
+
+//Removes the record stored under the key from the Backend, if there is one
+func (ss *SessionStore[TValue]) removeFromBackend(key string) {
+	if b := ss.req().Backend; b != nil {
+		if err := ss.protect("Backend.Remove", func() error { return b.Remove(key) }); err != nil {
+			ss.reportError(fmt.Errorf("sessions: removing moved key from backend: %w", err))
+		}
+	}
+}
+
+//Moves the session stored under one of the previous keys to the primary one, waking it up if it's hibernated.
+//Returns nil if it's not found
+func (ss *SessionStore[TValue]) rekey(uid string) *Session[TValue] {
+	for _, old := range ss.previousStorageKeys(uid) {
+		s, exist := ss._sessions.Get(old)
+		if !exist {
+			if s = ss.wake(old); s == nil {
+				continue
+			}
+		}
+
+		ss.move(s, old)
+		ss.removeFromBackend(old)
+
+		return s
+	}
+
+	return nil
+}
+
+//RotateKeys makes newKey the primary key of Requirements.Keys. Previous keys stay valid for verification, so existing
+//cookies and stored sessions keep working: sessions are moved to the new storage key when they are next looked up,
+//and cookies signed with a previous key are re-issued by Middleware. Only Requirements.MaxKeys newest keys are kept.
+//Sessions still stored under a key that is dropped are moved to the new one right away. The first key can only be
+//added while the store has no sessions, as the ones issued without keys carry unsigned cookies that stop being
+//accepted and would be left unreachable, so ErrUnkeyedSessions is returned. Set Requirements.Keys up front instead
+func (ss *SessionStore[TValue]) RotateKeys(newKey []byte) error {
+	ss.ready()
+
+	if len(ss.req().Keys) == 0 && ss._sessions.Count()+ss._hibernated.Count() > 0 {
+		return ErrUnkeyedSessions
+	}
+
+	dropped := false
+
+	err := ss.UpdateRequirements(func(r *Requirements[TValue]) {
+		keys := append([][]byte{newKey}, r.Keys...)
+
+		if limit := r.MaxKeys; limit > 0 && len(keys) > limit {
+			keys = keys[:limit]
+			dropped = true
+		}
+
+		r.Keys = keys
+	})
+	if err != nil || !dropped {
+		return err
+	}
+
+	ss.rekeyDropped()
+
+	return nil
+}
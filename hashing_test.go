@@ -0,0 +1,194 @@
+package sessions
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSession_RoutingKey(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	s := ss.New("value").(*Session[string])
+
+	key := s.RoutingKey()
+	if len(key) != 16 || key != RoutingKey(s.Uid()) || strings.Contains(s.Uid(), key) {
+		t.Errorf("Expected a 16 char routing key derived from the UID, got \"%s\"", key)
+	}
+
+	if s.RoutingKey() != key {
+		t.Errorf("Expected the routing key to be stable")
+	}
+
+	s.Regenerate()
+	if s.RoutingKey() == key {
+		t.Errorf("Expected the routing key to change together with the UID")
+	}
+}
+
+func TestRequirements_NormalizeUid(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{
+		NormalizeUid: func(uid string) string {
+			return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(uid)), "sess_")
+		},
+	})
+	s := ss.New("value")
+
+	if s.Uid() != strings.ToLower(s.Uid()) {
+		t.Errorf("Expected the UID to be normalized when the session is created")
+	}
+
+	if ss.Get(" SESS_"+strings.ToUpper(s.Uid())) != s {
+		t.Errorf("Expected a mangled UID to resolve to the session")
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: s.Key(), Value: strings.ToUpper(s.Uid())})
+	if got, err := ss.GetFromRequest(nil, r); got != s {
+		t.Errorf("Expected a mangled cookie to resolve to the session, got %v", err)
+	}
+}
+
+func FuzzParseCookieValue(f *testing.F) {
+	signed := initializeSessionStore(0, &Requirements[string]{Keys: [][]byte{bytes.Repeat([]byte("k"), 32)}})
+	plain := initializeSessionStore(0, nil)
+
+	s := signed.New("value").(*Session[string])
+	valid := signed.cookieValue(s.Uid())
+
+	f.Add(valid)
+	f.Add(s.Uid())
+	f.Add("")
+	f.Add(".")
+	f.Add("." + valid)
+	f.Add(valid + ".")
+	f.Add(valid[:len(valid)-1])
+
+	//The same signature with different trailing bits
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	f.Add(valid[:len(valid)-1] + string(alphabet[strings.IndexByte(alphabet, valid[len(valid)-1])^1]))
+	f.Add(valid[:len(valid)-4] + "\r" + valid[len(valid)-4:])
+
+	f.Fuzz(func(t *testing.T, value string) {
+		if uid, stale, ok := signed.parseCookieValue(value); ok {
+			if stale || signed.cookieValue(uid) != value {
+				t.Errorf("Expected only the value signed for \"%s\" to be accepted, got \"%s\"", uid, value)
+			}
+		}
+
+		if uid, _, ok := plain.parseCookieValue(value); ok && (uid == "" || uid != value) {
+			t.Errorf("Expected unsigned value \"%s\" to be taken as is, got \"%s\"", value, uid)
+		}
+	})
+}
+
+func TestRequirements_HashUid(t *testing.T) {
+	backend := newTestBackend()
+	ss := initializeSessionStore(0, &Requirements[string]{HashUid: SHA256UidHasher, Backend: backend})
+	s := ss.New("value").(*Session[string])
+
+	if s.StorageKey() != SHA256UidHasher(s.Uid()) {
+		t.Errorf("Expected storage key to be the hash of the UID")
+	}
+
+	if ss.Get(s.Uid()) == nil {
+		t.Errorf("Expected session to be found by its raw UID")
+	}
+
+	if ss.Get(s.StorageKey()) != nil {
+		t.Errorf("Expected session not to be found by its storage key")
+	}
+
+	_ = ss.FlushToBackend()
+
+	if _, exist := backend.records[s.Uid()]; exist {
+		t.Errorf("Expected backend not to see the raw UID")
+	}
+}
+
+func TestSessionStore_RotateKeys(t *testing.T) {
+	oldKey := bytes.Repeat([]byte("a"), 32)
+	ss := initializeSessionStore(0, &Requirements[string]{Keys: [][]byte{oldKey}})
+	s := ss.New("value").(*Session[string])
+
+	oldCookie := s.CookieHeaderValue()
+
+	if err := ss.RotateKeys(bytes.Repeat([]byte("b"), 32)); err != nil {
+		t.Fatalf("Expected rotation to succeed, got %s", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Cookie", strings.Split(oldCookie, ";")[0])
+	w := httptest.NewRecorder()
+
+	var found ISession[string]
+	ss.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		found = FromContext[string](r.Context())
+	})).ServeHTTP(w, req)
+
+	if found == nil || found.Value() != "value" {
+		t.Fatalf("Expected the session to be found with a cookie signed by the previous key")
+	}
+
+	newCookie := w.Header().Get("Set-Cookie")
+	if newCookie == "" || newCookie == oldCookie {
+		t.Errorf("Expected the cookie to be re-issued with the new key, got \"%s\"", newCookie)
+	}
+
+	tampered := &http.Cookie{Name: s.Key(), Value: s.Uid() + ".forged"}
+	if ss.GetFromCookie(&testHttpRequest{tampered}) != nil {
+		t.Errorf("Expected a cookie with an invalid signature to be rejected")
+	}
+}
+
+func TestSessionStore_RotateKeys_PreviousKeys(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Keys:             [][]byte{bytes.Repeat([]byte("a"), 32)},
+		MaxKeys:          2,
+		TombstoneTimeout: time.Hour,
+	})
+	removed, kept := ss.New("removed"), ss.New("kept")
+
+	if err := ss.RotateKeys(bytes.Repeat([]byte("b"), 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !ss.Exist(kept.Uid()) || !ss.Exist(removed.Uid()) {
+		t.Errorf("Expected sessions under the previous key to exist")
+	}
+
+	ss.Remove(removed.Uid())
+	if ss.Exist(removed.Uid()) || !ss.IsRevoked(removed.Uid()) || ss.Stats().Active != 1 {
+		t.Errorf("Expected the session under the previous key to be removed and revoked")
+	}
+
+	//The first key is dropped, so the session is moved to the primary key right away
+	if err := ss.RotateKeys(bytes.Repeat([]byte("c"), 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !ss._sessions.Exist(ss.storageKey(kept.Uid())) || ss.Get(kept.Uid()) != kept {
+		t.Errorf("Expected the session under the dropped key to be moved to the primary one")
+	}
+}
+
+func TestSessionStore_RotateKeys_First(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	s := ss.New("value")
+
+	if err := ss.RotateKeys(bytes.Repeat([]byte("a"), 32)); err != ErrUnkeyedSessions {
+		t.Errorf("Expected the first key to be refused while sessions exist, got %v", err)
+	}
+	if ss.Get(s.Uid()) != s {
+		t.Errorf("Expected the session to stay reachable")
+	}
+
+	ss.Remove(s.Uid())
+	if err := ss.RotateKeys(bytes.Repeat([]byte("a"), 32)); err != nil {
+		t.Errorf("Expected the first key to be added to an empty store, got %s", err)
+	}
+}
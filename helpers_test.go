@@ -0,0 +1,285 @@
+package sessions
+
+import (
+	"net/http"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+)
+
+//Clock that only moves when Advance is called. Timers due by then run synchronously in Advance, in the order of their
+//deadlines, so tests can expire sessions without sleeping
+type fakeClock struct {
+	now    time.Time
+	timers []*fakeTimer
+	mx     sync.Mutex
+}
+
+//Timer of the fakeClock
+type fakeTimer struct {
+	clock *fakeClock
+	at    time.Time
+	f     func()
+	done  bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mx.Lock()
+	defer t.clock.mx.Unlock()
+
+	stopped := !t.done
+	t.done = true
+
+	return stopped
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) clockTimer {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	t := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+
+	return t
+}
+
+//Advance moves the clock forward, running every timer that becomes due. Timers armed by them run as well if they're
+//due by the new time. Advance(0) runs the timers that are already due
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mx.Lock()
+	until := c.now.Add(d)
+	c.mx.Unlock()
+
+	for {
+		c.mx.Lock()
+
+		var next *fakeTimer
+		for _, t := range c.timers {
+			if !t.done && !t.at.After(until) && (next == nil || t.at.Before(next.at)) {
+				next = t
+			}
+		}
+
+		if next == nil {
+			c.now = until
+			c.timers = slices.DeleteFunc(c.timers, func(t *fakeTimer) bool { return t.done })
+			c.mx.Unlock()
+			return
+		}
+
+		next.done = true
+		if next.at.After(c.now) {
+			c.now = next.at
+		}
+		c.mx.Unlock()
+
+		next.f()
+	}
+}
+
+//Creates a store running on a fakeClock, see clockOf, with n sessions in it
+func initializeSessionStore(n int, r *Requirements[string]) *SessionStore[string] {
+	s := &SessionStore[string]{}
+	s.clock = &fakeClock{now: time.Now()}
+	s.setup(makeRequirementsReasonable(r))
+
+	for ; n > 0; n-- {
+		s.New(string(rune(n)))
+	}
+
+	return s
+}
+
+//Returns the fakeClock of a store created by initializeSessionStore
+func clockOf[TValue any](ss *SessionStore[TValue]) *fakeClock {
+	return ss.clock.(*fakeClock)
+}
+
+type testHttpRequest struct {
+	cookie *http.Cookie
+}
+
+func (t *testHttpRequest) Cookie(s string) (*http.Cookie, error) {
+	return t.cookie, nil
+}
+
+type testBackendRecord struct {
+	value   string
+	version uint64
+}
+
+type testBackend struct {
+	records map[string]testBackendRecord
+	mx      sync.Mutex
+}
+
+func newTestBackend() *testBackend {
+	return &testBackend{records: make(map[string]testBackendRecord)}
+}
+
+func (b *testBackend) Load(uid string) (string, uint64, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	r, exist := b.records[uid]
+	if !exist {
+		return "", 0, ErrNotFound
+	}
+
+	return r.value, r.version, nil
+}
+
+func (b *testBackend) Save(s ISession[string], _ []string, expectedVersion uint64) (uint64, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	if b.records[StorageKeyOf(s)].version != expectedVersion {
+		return 0, ErrVersionConflict
+	}
+
+	b.records[StorageKeyOf(s)] = testBackendRecord{s.Value(), expectedVersion + 1}
+
+	return expectedVersion + 1, nil
+}
+
+func (b *testBackend) Remove(uid string) error {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	delete(b.records, uid)
+	return nil
+}
+
+//Backend calling the hooks while a session is being loaded or saved, e.g. to change the store in the meantime
+type hookBackend struct {
+	*testBackend
+	onLoad func()
+	onSave func()
+}
+
+func (b *hookBackend) Load(uid string) (string, uint64, error) {
+	if b.onLoad != nil {
+		b.onLoad()
+	}
+	return b.testBackend.Load(uid)
+}
+
+func (b *hookBackend) Save(s ISession[string], dirtyFields []string, expectedVersion uint64) (uint64, error) {
+	if b.onSave != nil {
+		b.onSave()
+	}
+	return b.testBackend.Save(s, dirtyFields, expectedVersion)
+}
+
+//Backend that pretends the first inserts hit a record written by another node
+type collidingBackend struct {
+	*testBackend
+	collisions int
+}
+
+func (b *collidingBackend) Save(s ISession[string], dirtyFields []string, expectedVersion uint64) (uint64, error) {
+	if expectedVersion == 0 && b.collisions > 0 {
+		b.collisions--
+		return 0, ErrVersionConflict
+	}
+
+	return b.testBackend.Save(s, dirtyFields, expectedVersion)
+}
+
+//Upserting backend without versions that detects overlapping and out of order writes of the same session
+type orderingBackend struct {
+	inFlight     map[string]bool
+	lastModified map[string]time.Time
+	violations   int
+	mx           sync.Mutex
+}
+
+func (b *orderingBackend) Load(string) (string, uint64, error) {
+	return "", 0, ErrNotFound
+}
+
+func (b *orderingBackend) Save(s ISession[string], _ []string, _ uint64) (uint64, error) {
+	key, lm := StorageKeyOf(s), s.LastModified()
+
+	b.mx.Lock()
+	if b.inFlight[key] || lm.Before(b.lastModified[key]) {
+		b.violations++
+	}
+	b.inFlight[key], b.lastModified[key] = true, lm
+	b.mx.Unlock()
+
+	//Letting other writers run while this one is in flight
+	runtime.Gosched()
+
+	b.mx.Lock()
+	b.inFlight[key] = false
+	b.mx.Unlock()
+
+	return 0, nil
+}
+
+func (b *orderingBackend) Remove(string) error {
+	return nil
+}
+
+//Backend keeping records of any type in a map, without version checks
+type mapBackend[TValue any] struct {
+	records map[string]TValue
+}
+
+func (b *mapBackend[TValue]) Load(key string) (TValue, uint64, error) {
+	v, exist := b.records[key]
+	if !exist {
+		return v, 0, ErrNotFound
+	}
+	return v, 1, nil
+}
+
+func (b *mapBackend[TValue]) Save(s ISession[TValue], _ []string, _ uint64) (uint64, error) {
+	b.records[StorageKeyOf(s)] = s.Value()
+	return 1, nil
+}
+
+func (b *mapBackend[TValue]) Remove(key string) error {
+	delete(b.records, key)
+	return nil
+}
+
+//Backend keeping records of the sessions it's given, as their capabilities describe them
+type recordBackend[TValue any] struct {
+	records map[string]SessionRecord[TValue]
+}
+
+func (b *recordBackend[TValue]) Load(key string) (TValue, uint64, error) {
+	rec, exist := b.records[key]
+	if !exist {
+		return rec.Value, 0, ErrNotFound
+	}
+	return rec.Value, rec.Version + 1, nil
+}
+
+func (b *recordBackend[TValue]) Save(s ISession[TValue], _ []string, expectedVersion uint64) (uint64, error) {
+	rec := recordOf(s)
+	rec.Version = expectedVersion + 1
+	b.records[StorageKeyOf(s)] = rec
+	return rec.Version, nil
+}
+
+func (b *recordBackend[TValue]) Remove(key string) error {
+	delete(b.records, key)
+	return nil
+}
+
+type panickingBackend struct {
+	*testBackend
+}
+
+func (b panickingBackend) Save(ISession[string], []string, uint64) (uint64, error) {
+	panic("backend")
+}
@@ -0,0 +1,242 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Returns how long the session has left before it expires. 0 means it doesn't expire
+func (ss *SessionStore[TValue]) remainingLifetime(s *Session[TValue]) time.Duration {
+	s.mx.RLock()
+	expiresAt, createdAt, lastModified := s.session.ExpiresAt, s.session.CreatedAt, s.session.LastModified
+	s.mx.RUnlock()
+
+	if !expiresAt.IsZero() {
+		return expiresAt.Sub(ss.now())
+	}
+
+	if deadline := ss.deadline(createdAt, lastModified); !deadline.IsZero() {
+		return deadline.Sub(ss.now())
+	}
+
+	return 0
+}
+
+//Moves the session out of memory, leaving only its metadata behind. The value is kept in the Backend only and loaded
+//back once it's used again, see lockAwake. The value is saved the way a flush would save it, so the two never
+//overlap. If the session is modified while its value is being saved, it's left in memory for the next run
+func (ss *SessionStore[TValue]) hibernate(key string, s *Session[TValue]) error {
+	s.flushMx.Lock()
+	defer s.flushMx.Unlock()
+
+	s.mx.RLock()
+	modifications, version := s.session.modifications, s.session.version
+	fields := sortedFields(s.session.dirtyFields)
+	s.mx.RUnlock()
+
+	if ss._modifiedSessions.GetValue(key) == s || version == 0 {
+		//A session that was never stored has no fields to patch, so it's written as a whole
+		if version == 0 {
+			fields = nil
+		}
+
+		if err := ss.saveToBackend(s, fields); err != nil {
+			return err
+		}
+	}
+
+	remaining := ss.remainingLifetime(s)
+	if remaining < 0 {
+		ss.removeKey(key)
+		return nil
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	//The value saved is no longer the value of the session, or the session has moved on
+	if s.session.modifications != modifications || ss.storageKey(s.session.Uid) != key || ss._sessions.GetValue(key) != s {
+		return nil
+	}
+
+	var zero TValue
+	s.session.Value = zero
+	s.session.dirtyFields = nil
+	s.session.hibernated = true
+
+	ss._modifiedSessions.Remove(key)
+	ss._hibernated.AddWithTimeout(key, s, remaining)
+	ss._sessions.Remove(key)
+
+	return nil
+}
+
+//Brings the hibernated session stored under the key back into memory, loading its value from the Backend. Sessions
+//removed in the meantime stay gone. Returns the session in memory under the key, which may have been woken by a
+//concurrent call, or nil if there is none
+func (ss *SessionStore[TValue]) wake(key string) *Session[TValue] {
+	s, exist := ss._hibernated.Get(key)
+	if !exist {
+		return ss._sessions.GetValue(key)
+	}
+
+	if ss._tombstones.Exist(key) {
+		ss._hibernated.Remove(key)
+		return nil
+	}
+
+	b := ss.req().Backend
+	if b == nil {
+		return nil
+	}
+
+	var value TValue
+	var version uint64
+	err := ss.protect("Backend.Load", func() (err error) {
+		value, version, err = b.Load(key)
+		return err
+	})
+	if err != nil {
+		ss.reportError(fmt.Errorf("sessions: waking hibernated session: %w", err))
+		return nil
+	}
+
+	s.mx.Lock()
+
+	//Woken by a concurrent call or removed while its value was being loaded
+	if !s.session.hibernated || ss._hibernated.GetValue(key) != s || ss._tombstones.Exist(key) {
+		s.mx.Unlock()
+		return ss._sessions.GetValue(key)
+	}
+
+	s.session.Value = value
+	s.session.version = version
+	s.session.hibernated = false
+
+	ss._hibernated.Remove(key)
+	ss._sessions.Add(key, s)
+	s.mx.Unlock()
+
+	ss.armTimer(s)
+	ss.reindex(s)
+
+	return s
+}
+
+//Returns the value of the hibernated session stored under the key, read from the Backend without bringing the
+//session back into memory
+func (ss *SessionStore[TValue]) hibernatedValue(key string) (TValue, error) {
+	var value TValue
+
+	b := ss.req().Backend
+	if b == nil {
+		return value, ErrNoBackend
+	}
+
+	err := ss.protect("Backend.Load", func() (err error) {
+		value, _, err = b.Load(key)
+		return err
+	})
+
+	return value, err
+}
+
+//Locks the session with lock, loading its value back from the Backend first if it's hibernated, so the value seen and
+//changed under the lock is the real one rather than the zero value left behind. Returns ErrNotLoaded, with the
+//session unlocked, if the value can't be loaded, e.g. because the session was removed in the meantime
+func (s *Session[TValue]) lockAwake(lock, unlock func()) error {
+	for {
+		lock()
+		if !s.session.hibernated {
+			return nil
+		}
+		uid := s.session.Uid
+		unlock()
+
+		if s.store.wake(s.store.storageKey(uid)) != s {
+			return ErrNotLoaded
+		}
+	}
+}
+
+//Calls f with every session of the store, whether in memory or hibernated
+func (ss *SessionStore[TValue]) forEachSession(f func(key string, s *Session[TValue])) {
+	ss._sessions.ForEach(f)
+	ss._hibernated.ForEach(f)
+}
+
+//Checks whether the session is still in the store under the key, whether in memory or hibernated
+func (ss *SessionStore[TValue]) holds(key string, s *Session[TValue]) bool {
+	return ss._sessions.GetValue(key) == s || ss._hibernated.GetValue(key) == s
+}
+
+//Hibernate moves sessions idle for longer than Requirements.HibernateAfter out of memory. Their values are written
+//to the Backend and loaded back when the sessions are next requested, while their metadata stays in memory. Sessions
+//with suspended expiry are left alone. It runs automatically every Requirements.HibernateAfter
+func (ss *SessionStore[TValue]) Hibernate() error {
+	ss.ready()
+
+	r := ss.req()
+	if r.Backend == nil {
+		return ErrNoBackend
+	}
+
+	if r.HibernateAfter <= 0 {
+		return nil
+	}
+
+	if err := ss.writable(); err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for key, s := range ss._sessions.GetAll() {
+		s.mx.RLock()
+		idle := ss.now().Sub(s.session.LastModified) > r.HibernateAfter && s.session.suspensions == 0
+		s.mx.RUnlock()
+
+		if !idle {
+			continue
+		}
+
+		if err := ss.hibernate(key, s); err != nil {
+			errs = append(errs, fmt.Errorf("sessions: hibernating session: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+//Makes sure hibernation runs periodically while there are sessions in memory
+func (ss *SessionStore[TValue]) scheduleHibernation() {
+	ss.mx.Lock()
+	defer ss.mx.Unlock()
+
+	if ss.hibernationRunning || ss.Requirements.HibernateAfter <= 0 || ss.Requirements.Backend == nil {
+		return
+	}
+
+	ss.hibernationRunning = true
+	ss.clock.AfterFunc(ss.Requirements.HibernateAfter, ss.hibernationSweep)
+}
+
+//Runs hibernation and schedules the next run for as long as there are sessions in memory
+func (ss *SessionStore[TValue]) hibernationSweep() {
+	if err := ss.Hibernate(); err != nil && err != ErrReadOnly {
+		ss.reportError(err)
+	}
+
+	ss.mx.Lock()
+	defer ss.mx.Unlock()
+
+	if ss._sessions.Count() == 0 || ss.Requirements.HibernateAfter <= 0 || ss.Requirements.Backend == nil {
+		ss.hibernationRunning = false
+		return
+	}
+
+	ss.clock.AfterFunc(ss.Requirements.HibernateAfter, ss.hibernationSweep)
+}
@@ -0,0 +1,160 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_Hibernate(t *testing.T) {
+	backend := newTestBackend()
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: backend, HibernateAfter: time.Hour})
+	s := ss.New("value").(*Session[string])
+	active := ss.New("active")
+
+	s.session.LastModified = time.Now().Add(-2 * time.Hour)
+
+	if err := ss.Hibernate(); err != nil {
+		t.Fatalf("Expected hibernation to succeed, got %s", err)
+	}
+
+	if st := ss.Stats(); st.Active != 1 || st.Hibernated != 1 {
+		t.Errorf("Expected 1 active and 1 hibernated session, got %+v", st)
+	}
+
+	if s.session.Value != "" {
+		t.Errorf("Expected hibernated session to release its value, got \"%s\"", s.session.Value)
+	}
+
+	if got := ss.Get(s.Uid()); got == nil || got.Value() != "value" {
+		t.Errorf("Expected hibernated session to be loaded back with its value")
+	}
+
+	if ss.Get(active.Uid()) == nil {
+		t.Errorf("Expected active session to stay in memory")
+	}
+}
+
+func TestSessionStore_HibernateModified(t *testing.T) {
+	backend := &hookBackend{testBackend: newTestBackend()}
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: backend, HibernateAfter: time.Hour})
+	s := ss.New("value").(*Session[string])
+
+	s.session.LastModified = ss.now().Add(-2 * time.Hour)
+	backend.onSave = func() {
+		backend.onSave = nil
+		s.SetValue("modified")
+	}
+
+	if err := ss.Hibernate(); err != nil {
+		t.Fatalf("Expected hibernation to succeed, got %s", err)
+	}
+
+	if st := ss.Stats(); st.Active != 1 || st.Hibernated != 0 {
+		t.Errorf("Expected the session modified while being saved to stay in memory, got %+v", st)
+	}
+
+	if s.Value() != "modified" || !ss._modifiedSessions.Exist(s.StorageKey()) {
+		t.Errorf("Expected the modification to be kept for the next flush, got \"%s\"", s.Value())
+	}
+}
+
+func TestSessionStore_WakeRemoved(t *testing.T) {
+	backend := &hookBackend{testBackend: newTestBackend()}
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Backend:          backend,
+		HibernateAfter:   time.Hour,
+		TombstoneTimeout: time.Hour,
+	})
+	s := ss.New("value")
+	uid := s.Uid()
+
+	clockOf(ss).Advance(2 * time.Hour)
+	if err := ss.Hibernate(); err != nil {
+		t.Fatal(err)
+	}
+
+	backend.onLoad = func() { ss.Remove(uid) }
+
+	if ss.Get(uid) != nil {
+		t.Errorf("Expected the session removed while waking up to stay gone")
+	}
+
+	if st := ss.Stats(); st.Active != 0 || st.Hibernated != 0 {
+		t.Errorf("Expected the removed session to be neither in memory nor hibernated, got %+v", st)
+	}
+}
+
+func TestSessionStore_HibernatedHolder(t *testing.T) {
+	backend := newTestBackend()
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: backend, HibernateAfter: time.Hour})
+	held := ss.New("value").(*Session[string])
+	other := ss.New("other").(*Session[string])
+
+	clockOf(ss).Advance(2 * time.Hour)
+	if err := ss.Hibernate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if st := ss.Stats(); st.Hibernated != 2 {
+		t.Fatalf("Expected both sessions to be hibernated, got %+v", st)
+	}
+
+	if held.Value() != "value" {
+		t.Errorf("Expected a holder of the session to read its value back, got \"%s\"", held.Value())
+	}
+
+	other.SetValue("changed")
+	if got := ss.Get(other.Uid()); got == nil || got.Value() != "changed" {
+		t.Fatalf("Expected a write through a hibernated session to be kept once it's woken")
+	}
+
+	if err := ss.FlushToBackend(); err != nil {
+		t.Fatal(err)
+	}
+	if backend.records[other.StorageKey()].value != "changed" {
+		t.Errorf("Expected the write through a hibernated session to be flushed")
+	}
+}
+
+func TestSessionStore_WakeConcurrently(t *testing.T) {
+	backend := &hookBackend{testBackend: newTestBackend()}
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: backend, HibernateAfter: time.Hour})
+	uid := ss.New("value").Uid()
+
+	clockOf(ss).Advance(2 * time.Hour)
+	if err := ss.Hibernate(); err != nil {
+		t.Fatal(err)
+	}
+
+	//The second wake finishes while the first one is still loading the value
+	var second ISession[string]
+	backend.onLoad = func() {
+		backend.onLoad = nil
+		second = ss.Get(uid)
+	}
+
+	if first := ss.Get(uid); first == nil || second == nil || first != second {
+		t.Errorf("Expected both concurrent lookups to find the woken session, got %v and %v", first, second)
+	}
+}
+
+func TestSessionStore_HibernateNeverStored(t *testing.T) {
+	backend := &fieldsBackend{testBackend: newTestBackend()}
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Backend:        backend,
+		HibernateAfter: time.Hour,
+		Differ:         func(old, new string) []string { return []string{"Text"} },
+	})
+	ss.New("a").SetValue("b")
+
+	clockOf(ss).Advance(2 * time.Hour)
+	if err := ss.Hibernate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backend.fields) != 1 || backend.fields[0] != nil {
+		t.Errorf("Expected a session never stored to be hibernated as a whole, got %v", backend.fields)
+	}
+}
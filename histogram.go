@@ -0,0 +1,70 @@
+package sessions
+
+import (
+	"sort"
+	"time"
+)
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Returns the label of the bucket the age falls into. Buckets must be sorted in ascending order
+func ageBucketLabel(age time.Duration, buckets []time.Duration) string {
+	for i, b := range buckets {
+		if age >= b {
+			continue
+		}
+
+		if i == 0 {
+			return "<" + b.String()
+		}
+
+		return buckets[i-1].String() + "-" + b.String()
+	}
+
+	return ">=" + buckets[len(buckets)-1].String()
+}
+
+//AgeHistogram counts the sessions, hibernated ones included, by their age (time elapsed since CreatedAt). Buckets are
+//upper bounds, e.g. []time.Duration{time.Minute, time.Hour} produces labels "<1m0s", "1m0s-1h0m0s" and ">=1h0m0s".
+//Every label is present in the result, even if no session falls into it. If no buckets are supplied, all the sessions
+//are counted under the label "all"
+func (ss *SessionStore[TValue]) AgeHistogram(buckets []time.Duration) map[string]int {
+	ss.ready()
+
+	return ss.histogram(buckets, (*Session[TValue]).CreatedAt)
+}
+
+//IdleHistogram counts the sessions by how long they have been idle (time elapsed since LastModified), e.g. to see how
+//close sessions get to Requirements.Timeout. Buckets and labels are the same as in AgeHistogram
+func (ss *SessionStore[TValue]) IdleHistogram(buckets []time.Duration) map[string]int {
+	ss.ready()
+
+	return ss.histogram(buckets, (*Session[TValue]).LastModified)
+}
+
+//Counts the sessions by time elapsed since the time returned by since
+func (ss *SessionStore[TValue]) histogram(buckets []time.Duration, since func(s *Session[TValue]) time.Time) map[string]int {
+	sorted := make([]time.Duration, len(buckets))
+	copy(sorted, buckets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	results := make(map[string]int)
+	now := ss.now()
+
+	if len(sorted) == 0 {
+		results["all"] = ss._sessions.Count() + ss._hibernated.Count()
+		return results
+	}
+
+	//Making sure that empty buckets are reported as well
+	for i := range sorted {
+		results[ageBucketLabel(sorted[i]-1, sorted)] = 0
+	}
+	results[ageBucketLabel(sorted[len(sorted)-1], sorted)] = 0
+
+	ss.forEachSession(func(_ string, s *Session[TValue]) {
+		results[ageBucketLabel(now.Sub(since(s)), sorted)]++
+	})
+
+	return results
+}
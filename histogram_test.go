@@ -0,0 +1,54 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_AgeHistogram(t *testing.T) {
+	ss := initializeSessionStore(3, nil)
+
+	old := ss.New("old").(*Session[string])
+	old.session.CreatedAt = time.Now().Add(-2 * time.Hour)
+
+	h := ss.AgeHistogram([]time.Duration{time.Hour, time.Minute})
+
+	if h["<1m0s"] != 3 {
+		t.Errorf("Expected 3 sessions under \"<1m0s\", got %d", h["<1m0s"])
+	}
+
+	if h["1m0s-1h0m0s"] != 0 {
+		t.Errorf("Expected 0 sessions under \"1m0s-1h0m0s\", got %d", h["1m0s-1h0m0s"])
+	}
+
+	if h[">=1h0m0s"] != 1 {
+		t.Errorf("Expected 1 session under \">=1h0m0s\", got %d", h[">=1h0m0s"])
+	}
+}
+
+func TestSessionStore_IdleHistogram(t *testing.T) {
+	ss := initializeSessionStore(2, nil)
+	clk := clockOf(ss)
+
+	idle := ss.New("idle")
+	clk.Advance(30 * time.Minute)
+	ss.New("active")
+	idle.SetValue("touched")
+	clk.Advance(10 * time.Minute)
+
+	h := ss.IdleHistogram([]time.Duration{time.Minute, 20 * time.Minute})
+
+	if h["<1m0s"] != 0 {
+		t.Errorf("Expected 0 sessions under \"<1m0s\", got %d", h["<1m0s"])
+	}
+
+	if h["1m0s-20m0s"] != 2 {
+		t.Errorf("Expected 2 sessions under \"1m0s-20m0s\", got %d", h["1m0s-20m0s"])
+	}
+
+	if h[">=20m0s"] != 2 {
+		t.Errorf("Expected 2 sessions under \">=20m0s\", got %d", h[">=20m0s"])
+	}
+}
@@ -0,0 +1,16 @@
+package sessions
+
+import "time"
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Import adds a session carried over from elsewhere, e.g. another session library, bypassing interceptors. The UID is
+//kept, so cookies already holding it stay valid; empty uid means a new one is generated. Non-zero expiresAt becomes
+//the absolute deadline of the session, see Session.ExpireAt. Returns ErrExists if the UID is already taken,
+//ErrExpired if expiresAt has already passed and ErrQuotaExceeded if the session doesn't fit the quotas. Use FromRecord
+//to carry over the rest of the session as well
+func (ss *SessionStore[TValue]) Import(uid string, data TValue, expiresAt time.Time) (ISession[TValue], error) {
+	ss.ready()
+
+	return ss.FromRecord(SessionRecord[TValue]{Uid: uid, Value: data, ExpiresAt: expiresAt})
+}
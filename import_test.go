@@ -0,0 +1,30 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_Import(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{Timeout: time.Hour})
+	deadline := time.Now().Add(time.Minute)
+
+	s, err := ss.Import("legacy-token", "value", deadline)
+	if err != nil {
+		t.Fatalf("Expected import to succeed, got %s", err)
+	}
+
+	if ss.Get("legacy-token") != s || !s.(*Session[string]).ExpiresAt().Equal(deadline) {
+		t.Errorf("Expected imported session to keep its UID and expiry")
+	}
+
+	if _, err = ss.Import("legacy-token", "other", time.Time{}); err != ErrExists {
+		t.Errorf("Expected importing a taken UID to fail with ErrExists, got %v", err)
+	}
+
+	if _, err = ss.Import("", "value", time.Now().Add(-time.Second)); err != ErrExpired {
+		t.Errorf("Expected importing an expired session to fail with ErrExpired, got %v", err)
+	}
+}
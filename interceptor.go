@@ -0,0 +1,73 @@
+package sessions
+
+//===========[STRUCTS]====================================================================================================
+
+//Interceptor wraps calls made to the SessionStore and its sessions. Every field is optional, nil ones are skipped.
+//Each function receives "next" which continues the chain, so it can alter arguments, inspect results or skip the
+//call altogether by not invoking "next"
+type Interceptor[TValue any] struct {
+	//New wraps SessionStore.New
+	New func(data TValue, next func(TValue) ISession[TValue]) ISession[TValue]
+
+	//Get wraps SessionStore.Get and SessionStore.GetFromCookie
+	Get func(uid string, next func(string) ISession[TValue]) ISession[TValue]
+
+	//SetValue wraps Session.SetValue
+	SetValue func(s ISession[TValue], v TValue, next func(TValue))
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Runs New through the interceptor chain, final being the actual implementation
+func (ss *SessionStore[TValue]) interceptNew(data TValue, final func(TValue) ISession[TValue]) ISession[TValue] {
+	next := final
+
+	interceptors := ss.req().Interceptors
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		f, n := interceptors[i].New, next
+		if f == nil {
+			continue
+		}
+
+		next = func(data TValue) ISession[TValue] { return f(data, n) }
+	}
+
+	return next(data)
+}
+
+//Runs Get through the interceptor chain, final being the actual implementation
+func (ss *SessionStore[TValue]) interceptGet(uid string, final func(string) ISession[TValue]) ISession[TValue] {
+	next := final
+
+	interceptors := ss.req().Interceptors
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		f, n := interceptors[i].Get, next
+		if f == nil {
+			continue
+		}
+
+		next = func(uid string) ISession[TValue] { return f(uid, n) }
+	}
+
+	return next(uid)
+}
+
+//Runs SetValue through the interceptor chain, final being the actual implementation
+func (ss *SessionStore[TValue]) interceptSetValue(s ISession[TValue], v TValue, final func(TValue)) {
+	next := final
+
+	interceptors := ss.req().Interceptors
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		f, n := interceptors[i].SetValue, next
+		if f == nil {
+			continue
+		}
+
+		next = func(v TValue) { f(s, v, n) }
+	}
+
+	next(v)
+}
@@ -0,0 +1,43 @@
+package sessions
+
+import "testing"
+
+//===========[TESTING]====================================================================================================
+
+func TestRequirements_Interceptors(t *testing.T) {
+	var calls []string
+
+	ss := initializeSessionStore(0, &Requirements[string]{Interceptors: []Interceptor[string]{
+		{
+			SetValue: func(s ISession[string], v string, next func(string)) {
+				calls = append(calls, "first")
+				next(v + "!")
+			},
+		},
+		{
+			New: func(data string, next func(string) ISession[string]) ISession[string] {
+				return next("new:" + data)
+			},
+			SetValue: func(s ISession[string], v string, next func(string)) {
+				calls = append(calls, "second")
+				next(v)
+			},
+		},
+	}})
+
+	s := ss.New("value")
+
+	if s.Value() != "new:value" {
+		t.Errorf("Expected the New interceptor to change value to \"new:value\", got \"%s\"", s.Value())
+	}
+
+	s.SetValue("hi")
+
+	if s.Value() != "hi!" {
+		t.Errorf("Expected the SetValue interceptor to change value to \"hi!\", got \"%s\"", s.Value())
+	}
+
+	if len(calls) != 2 || calls[0] != "first" || calls[1] != "second" {
+		t.Errorf("Expected interceptors to be called in order [first second], got %v", calls)
+	}
+}
@@ -0,0 +1,78 @@
+package sessions
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//JournalEntry records a single operation on a session. It carries no values and no UIDs, sessions are identified by
+//their RoutingKey
+type JournalEntry struct {
+	Time       time.Time `json:"time" bson:"time"`
+	Op         string    `json:"op" bson:"op"`
+	RoutingKey string    `json:"routing_key" bson:"routing_key"`
+	Label      string    `json:"label" bson:"label"`
+}
+
+//Bounded log of operations on the sessions of the store
+type journal struct {
+	entries []JournalEntry
+
+	mx sync.Mutex
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Records the change of the session in the journal, if it's enabled by Requirements.JournalSize or
+//Requirements.JournalWriter
+func (ss *SessionStore[TValue]) journalRecord(kind ChangeKind, s *Session[TValue]) {
+	r := ss.req()
+	if r.JournalSize <= 0 && r.JournalWriter == nil {
+		return
+	}
+
+	e := JournalEntry{Time: ss.now(), Op: kind.String(), RoutingKey: s.RoutingKey(), Label: s.Label()}
+
+	j := &ss.journal
+	j.mx.Lock()
+	defer j.mx.Unlock()
+
+	if r.JournalSize > 0 {
+		j.entries = append(j.entries, e)
+		if len(j.entries) > r.JournalSize {
+			j.entries = j.entries[len(j.entries)-r.JournalSize:]
+		}
+	}
+
+	if r.JournalWriter != nil {
+		b, err := json.Marshal(e)
+		if err == nil {
+			err = ss.protect("JournalWriter", func() (err error) {
+				_, err = r.JournalWriter.Write(append(b, '\n'))
+				return err
+			})
+		}
+		if err != nil {
+			ss.reportError(fmt.Errorf("sessions: writing journal: %w", err))
+		}
+	}
+}
+
+//Journal returns the entries recorded at or after since, oldest first. Only the last Requirements.JournalSize entries
+//are kept in memory
+func (ss *SessionStore[TValue]) Journal(since time.Time) []JournalEntry {
+	ss.ready()
+
+	j := &ss.journal
+	j.mx.Lock()
+	defer j.mx.Unlock()
+
+	i := sort.Search(len(j.entries), func(i int) bool { return !j.entries[i].Time.Before(since) })
+
+	return append([]JournalEntry(nil), j.entries[i:]...)
+}
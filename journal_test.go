@@ -0,0 +1,35 @@
+package sessions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_Journal(t *testing.T) {
+	var file bytes.Buffer
+	ss := initializeSessionStore(0, &Requirements[string]{JournalSize: 2, JournalWriter: &file})
+
+	s := ss.New("secret value")
+	key := s.(*Session[string]).RoutingKey()
+	clockOf(ss).Advance(time.Second)
+	since := clockOf(ss).Now()
+	s.SetValue("changed")
+	ss.Remove(s.Uid())
+
+	entries := ss.Journal(since)
+	if len(entries) != 2 || entries[0].Op != "value" || entries[1].Op != "removed" || entries[1].RoutingKey != key {
+		t.Errorf("Expected the update and the removal to be journaled, got %+v", entries)
+	}
+
+	if n := strings.Count(file.String(), "\n"); n != 3 {
+		t.Errorf("Expected 3 entries to be written, got %d", n)
+	}
+
+	if strings.Contains(file.String(), "secret") || strings.Contains(file.String(), s.Uid()) {
+		t.Errorf("Expected the journal to carry no values or UIDs")
+	}
+}
@@ -0,0 +1,65 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//ErrIndexOutOfRange is returned by RemoveValueAt when the index doesn't exist in the value
+var ErrIndexOutOfRange = errors.New("sessions: index out of range")
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//AppendValue appends items to the slice value of the session under the session lock, so concurrent requests, e.g.
+//adding items to a cart, don't overwrite each other. The session must implement Updater, otherwise
+//errors.ErrUnsupported is returned
+func AppendValue[E any](s ISession[[]E], items ...E) error {
+	u, ok := s.(Updater[[]E])
+	if !ok {
+		return errors.ErrUnsupported
+	}
+
+	return u.UpdateE(func(v *[]E) {
+		//Full slice expression makes append copy, so slices returned by Value earlier are never written to
+		*v = append((*v)[:len(*v):len(*v)], items...)
+	})
+}
+
+//RemoveValueAt removes the item at index i from the slice value of the session under the session lock
+func RemoveValueAt[E any](s ISession[[]E], i int) error {
+	u, ok := s.(Updater[[]E])
+	if !ok {
+		return errors.ErrUnsupported
+	}
+
+	if n := len(s.Value()); i < 0 || i >= n {
+		return fmt.Errorf("%w: %d of %d", ErrIndexOutOfRange, i, n)
+	}
+
+	//The value might have shrunk since it was checked
+	var err error
+
+	updateErr := u.UpdateE(func(v *[]E) {
+		if i < 0 || i >= len(*v) {
+			err = fmt.Errorf("%w: %d of %d", ErrIndexOutOfRange, i, len(*v))
+			return
+		}
+
+		removed := make([]E, 0, len(*v)-1)
+		removed = append(removed, (*v)[:i]...)
+		*v = append(removed, (*v)[i+1:]...)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return updateErr
+}
+
+//LenValue returns the length of the slice value of the session
+func LenValue[E any](s ISession[[]E]) int {
+	return len(s.Value())
+}
@@ -0,0 +1,37 @@
+package sessions
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestAppendValue(t *testing.T) {
+	ss := New[[]string](nil)
+	s := ss.New(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = AppendValue(s, "item")
+		}()
+	}
+	wg.Wait()
+
+	if n := LenValue(s); n != 50 {
+		t.Errorf("Expected 50 items after concurrent appends, got %d", n)
+	}
+
+	if err := RemoveValueAt(s, 50); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	_ = AppendValue(s, "last")
+	if err := RemoveValueAt(s, 0); err != nil || LenValue(s) != 50 || s.Value()[49] != "last" {
+		t.Errorf("Expected the first item to be removed, got %v", err)
+	}
+}
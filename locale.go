@@ -0,0 +1,69 @@
+package sessions
+
+import (
+	"golang.org/x/text/language"
+	"net/http"
+	"time"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+var (
+	//Attribute the locale of the session is stored in
+	localeAttr = NewAttrKey[language.Tag]("sessions.locale")
+
+	//Attribute the time zone of the session is stored in
+	timezoneAttr = NewAttrKey[*time.Location]("sessions.timezone")
+)
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//SetLocale stores the preferred locale of the user in the session
+func (s *Session[TValue]) SetLocale(tag language.Tag) {
+	localeAttr.Set(s, tag)
+}
+
+//Locale returns the locale stored in the session, or language.Und if there is none
+func (s *Session[TValue]) Locale() language.Tag {
+	tag, _ := localeAttr.Get(s)
+	return tag
+}
+
+//SetTimezone stores the time zone of the user in the session
+func (s *Session[TValue]) SetTimezone(loc *time.Location) {
+	timezoneAttr.Set(s, loc)
+}
+
+//Timezone returns the time zone stored in the session, or time.UTC if there is none
+func (s *Session[TValue]) Timezone() *time.Location {
+	if loc, ok := timezoneAttr.Get(s); ok && loc != nil {
+		return loc
+	}
+
+	return time.UTC
+}
+
+//LocaleMiddleware picks the locale of sessions that don't have one yet from the Accept-Language header, choosing
+//the best match among the supported locales, the first of them being the fallback. Requests without a session are
+//passed through untouched, and so are sessions while the store is read-only. Use it after Middleware, or it will load
+//the session from the cookie itself
+func (ss *SessionStore[TValue]) LocaleMiddleware(supported []language.Tag, next http.Handler) http.Handler {
+	ss.ready()
+
+	matcher := language.NewMatcher(supported)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := FromContext[TValue](r.Context())
+		if s == nil {
+			s = ss.tenantSession(r)
+		}
+
+		if l, ok := s.(Localizer); ok && l.Locale() == language.Und && len(supported) > 0 && !ss.ReadOnly() {
+			tags, _, _ := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+			_, i, _ := matcher.Match(tags...)
+			l.SetLocale(supported[i])
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,38 @@
+package sessions
+
+import (
+	"golang.org/x/text/language"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_LocaleMiddleware(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	s := ss.New("value").(*Session[string])
+
+	if s.Locale() != language.Und || s.Timezone() != time.UTC {
+		t.Errorf("Expected no locale and UTC by default")
+	}
+
+	h := ss.LocaleMiddleware([]language.Tag{language.English, language.German}, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: s.Key(), Value: s.Uid()})
+	r.Header.Set("Accept-Language", "de-CH, fr;q=0.8")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if base, _ := s.Locale().Base(); base.String() != "de" {
+		t.Errorf("Expected German to be negotiated, got %s", s.Locale())
+	}
+
+	s.SetLocale(language.English)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if s.Locale() != language.English {
+		t.Errorf("Expected the stored locale to be kept, got %s", s.Locale())
+	}
+}
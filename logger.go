@@ -0,0 +1,31 @@
+package sessions
+
+import "log/slog"
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//Number of hex characters of the hashed UID used to identify sessions in logs
+const logIdLength = 12
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Returns truncated SHA-256 of the uid. It correlates log lines without revealing the uid itself
+func hashedLogId(uid string) string {
+	return SHA256UidHasher(uid)[:logIdLength]
+}
+
+//Logger returns base logger annotated with the "session" group holding hashed session ID and the label, so log lines
+//of different services can be correlated by session without leaking the UID. If base is nil, slog.Default() is used
+func (s *Session[TValue]) Logger(base *slog.Logger) *slog.Logger {
+	if base == nil {
+		base = slog.Default()
+	}
+
+	attrs := []any{slog.String("id", hashedLogId(s.Uid()))}
+
+	if label := s.Label(); label != "" {
+		attrs = append(attrs, slog.String("label", label))
+	}
+
+	return base.With(slog.Group("session", attrs...))
+}
@@ -0,0 +1,28 @@
+package sessions
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSession_Logger(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	s := ss.NewLabeled("value", "mobile").(*Session[string])
+
+	var buf bytes.Buffer
+	s.Logger(slog.New(slog.NewTextHandler(&buf, nil))).Info("hello")
+
+	out := buf.String()
+
+	if !strings.Contains(out, "session.id="+hashedLogId(s.Uid())) || !strings.Contains(out, "session.label=mobile") {
+		t.Errorf("Expected log line to contain hashed session id and label, got \"%s\"", out)
+	}
+
+	if strings.Contains(out, s.Uid()) {
+		t.Errorf("Expected log line not to contain the raw UID")
+	}
+}
@@ -0,0 +1,70 @@
+package sessions
+
+import (
+	"context"
+	"net/http"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//Key under which Middleware stores the session in the request context
+type contextKey[TValue any] struct{}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Middleware loads the session from the request cookie and stores it in the request context, where FromContext finds
+//it. Cookies signed with a previous key are re-issued with the current one. Bad cookies are handled as described in
+//GetFromRequest
+func (ss *SessionStore[TValue]) Middleware(next http.Handler) http.Handler {
+	ss.ready()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s, _ := ss.GetFromRequest(w, r); s != nil {
+			if c, ok := s.(CookieWriter); ok && c.CookieStale() {
+				c.SetHttpCookie(w, nil)
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), contextKey[TValue]{}, s))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+//Require returns middleware that lets requests through only if they carry a session the predicate accepts, e.g. one
+//of a logged in user or an admin. Other requests are handed to onFail, or answered with 401 Unauthorized if it's nil.
+//The predicate is never called with nil. The session is taken from the context if Middleware has already loaded it,
+//otherwise from the request cookie, so guards can be stacked inside Middleware or used on their own
+func (ss *SessionStore[TValue]) Require(pred func(s ISession[TValue]) bool, onFail http.Handler) func(next http.Handler) http.Handler {
+	ss.ready()
+
+	if onFail == nil {
+		onFail = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		})
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s := FromContext[TValue](r.Context())
+			if s == nil {
+				if s, _ = ss.GetFromRequest(w, r); s != nil {
+					r = r.WithContext(context.WithValue(r.Context(), contextKey[TValue]{}, s))
+				}
+			}
+
+			if s == nil || (pred != nil && !pred(s)) {
+				onFail.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+//FromContext returns the session stored in the context by Middleware, or nil if there is none
+func FromContext[TValue any](ctx context.Context) ISession[TValue] {
+	s, _ := ctx.Value(contextKey[TValue]{}).(ISession[TValue])
+	return s
+}
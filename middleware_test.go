@@ -0,0 +1,36 @@
+package sessions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_Require(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	admin, user := ss.New("admin"), ss.New("user")
+
+	isAdmin := func(s ISession[string]) bool { return s.Value() == "admin" }
+	h := ss.Middleware(ss.Require(isAdmin, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	for _, tc := range []struct {
+		session ISession[string]
+		status  int
+	}{{admin, http.StatusNoContent}, {user, http.StatusUnauthorized}, {nil, http.StatusUnauthorized}} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tc.session != nil {
+			r.AddCookie(&http.Cookie{Name: tc.session.Key(), Value: tc.session.Uid()})
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != tc.status {
+			t.Errorf("Expected status %d, got %d", tc.status, w.Code)
+		}
+	}
+}
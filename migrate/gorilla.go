@@ -0,0 +1,167 @@
+package migrate
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"github.com/emillis/sessions"
+	"strconv"
+	"time"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+var (
+	//ErrInvalidCookie is returned when the cookie value is malformed or its MAC doesn't match
+	ErrInvalidCookie = errors.New("migrate: invalid cookie")
+
+	//ErrCookieExpired is returned when the cookie is older than GorillaCodec.MaxAge
+	ErrCookieExpired = errors.New("migrate: cookie expired")
+)
+
+//Default max age of gorilla/sessions cookies
+const defaultGorillaMaxAge = 30 * 24 * time.Hour
+
+//===========[STRUCTURES]===============================================================================================
+
+//GorillaCodec decodes cookies written by gorilla/sessions CookieStore, i.e. values encoded by gorilla/securecookie
+//with the default SHA-256 hash function
+type GorillaCodec struct {
+	//HashKey is the key the cookies were authenticated with
+	HashKey []byte `json:"hash_key" bson:"hash_key"`
+
+	//BlockKey is the AES key the cookies were encrypted with. nil means they are not encrypted
+	BlockKey []byte `json:"block_key" bson:"block_key"`
+
+	//MaxAge is the lifetime of the cookies, as in sessions.Options.MaxAge. Zero means 30 days, the gorilla default
+	MaxAge time.Duration `json:"max_age" bson:"max_age"`
+
+	//JSON must be set if the values were serialized with securecookie.JSONEncoder instead of the default gob
+	JSON bool `json:"json" bson:"json"`
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Returns the lifetime of the cookies
+func (c *GorillaCodec) maxAge() time.Duration {
+	if c.MaxAge <= 0 {
+		return defaultGorillaMaxAge
+	}
+
+	return c.MaxAge
+}
+
+//Decode verifies and decodes the value of the cookie with the name, returning the values of the gorilla session and
+//the time the cookie was issued. Custom types stored in the session must be registered with gob.Register, same as
+//they were for gorilla
+func (c *GorillaCodec) Decode(name, value string) (map[any]any, time.Time, error) {
+	raw, err := base64.URLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, time.Time{}, ErrInvalidCookie
+	}
+
+	//Format: timestamp|value|mac, where mac covers name|timestamp|value
+	parts := bytes.SplitN(raw, []byte("|"), 3)
+	if len(parts) != 3 {
+		return nil, time.Time{}, ErrInvalidCookie
+	}
+
+	mac := hmac.New(sha256.New, c.HashKey)
+	mac.Write([]byte(name + "|"))
+	mac.Write(raw[:len(raw)-len(parts[2])-1])
+	if !hmac.Equal(parts[2], mac.Sum(nil)) {
+		return nil, time.Time{}, ErrInvalidCookie
+	}
+
+	ts, err := strconv.ParseInt(string(parts[0]), 10, 64)
+	if err != nil {
+		return nil, time.Time{}, ErrInvalidCookie
+	}
+
+	issued := time.Unix(ts, 0)
+	if time.Since(issued) > c.maxAge() {
+		return nil, time.Time{}, ErrCookieExpired
+	}
+
+	b, err := base64.URLEncoding.DecodeString(string(parts[1]))
+	if err != nil {
+		return nil, time.Time{}, ErrInvalidCookie
+	}
+
+	if c.BlockKey != nil {
+		if b, err = decryptCTR(c.BlockKey, b); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+
+	values, err := c.deserialize(b)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return values, issued, nil
+}
+
+//Decrypts AES-CTR encrypted data prefixed with the IV
+func decryptCTR(key, b []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	size := block.BlockSize()
+	if len(b) <= size {
+		return nil, ErrInvalidCookie
+	}
+
+	out := make([]byte, len(b)-size)
+	cipher.NewCTR(block, b[:size]).XORKeyStream(out, b[size:])
+
+	return out, nil
+}
+
+//Decodes values of the session
+func (c *GorillaCodec) deserialize(b []byte) (map[any]any, error) {
+	if !c.JSON {
+		values := map[any]any{}
+		if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&values); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	values := make(map[any]any, len(m))
+	for k, v := range m {
+		values[k] = v
+	}
+
+	return values, nil
+}
+
+//ImportGorilla decodes the gorilla session cookie and adds it to the store as a new session that expires when the
+//cookie would have. convert turns the gorilla values into the value of the session. The new session gets its own
+//UID, so its cookie has to be set on the response
+func ImportGorilla[TValue any](ss *sessions.SessionStore[TValue], codec *GorillaCodec, name, value string, convert func(values map[any]any) (TValue, error)) (sessions.ISession[TValue], error) {
+	values, issued, err := codec.Decode(name, value)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := convert(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return ss.Import("", data, issued.Add(codec.maxAge()))
+}
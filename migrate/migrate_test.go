@@ -0,0 +1,117 @@
+package migrate
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/emillis/sessions"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+//Encodes the values the same way gorilla/securecookie does
+func encodeGorilla(t *testing.T, hashKey, blockKey []byte, name string, issued time.Time, values map[any]any) string {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(values); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+
+	if blockKey != nil {
+		block, err := aes.NewCipher(blockKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		iv := make([]byte, block.BlockSize())
+		cipher.NewCTR(block, iv).XORKeyStream(b, b)
+		b = append(iv, b...)
+	}
+
+	b = []byte(fmt.Sprintf("%s|%d|%s|", name, issued.Unix(), base64.URLEncoding.EncodeToString(b)))
+	mac := hmac.New(sha256.New, hashKey)
+	mac.Write(b[:len(b)-1])
+	b = append(b, mac.Sum(nil)...)[len(name)+1:]
+
+	return base64.URLEncoding.EncodeToString(b)
+}
+
+func TestImportGorilla(t *testing.T) {
+	ss := sessions.New[string](nil)
+	hashKey, blockKey := bytes.Repeat([]byte("h"), 32), bytes.Repeat([]byte("b"), 16)
+	codec := &GorillaCodec{HashKey: hashKey, BlockKey: blockKey, MaxAge: time.Hour}
+	issued := time.Now().Add(-time.Minute)
+
+	value := encodeGorilla(t, hashKey, blockKey, "session", issued, map[any]any{"user": "alice"})
+	convert := func(values map[any]any) (string, error) {
+		user, _ := values["user"].(string)
+		return user, nil
+	}
+
+	s, err := ImportGorilla(ss, codec, "session", value, convert)
+	if err != nil {
+		t.Fatalf("Expected gorilla cookie to be imported, got %s", err)
+	}
+
+	if s.Value() != "alice" {
+		t.Errorf("Expected imported value to be \"alice\", got \"%s\"", s.Value())
+	}
+
+	if got, want := s.(sessions.Expirer).ExpiresAt(), time.Unix(issued.Unix(), 0).Add(time.Hour); !got.Equal(want) {
+		t.Errorf("Expected imported session to expire at %s, got %s", want, got)
+	}
+
+	if _, err = ImportGorilla(ss, codec, "other", value, convert); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("Expected cookie under a different name to be rejected, got %v", err)
+	}
+
+	old := encodeGorilla(t, hashKey, blockKey, "session", time.Now().Add(-2*time.Hour), map[any]any{})
+	if _, err = ImportGorilla(ss, codec, "session", old, convert); !errors.Is(err, ErrCookieExpired) {
+		t.Errorf("Expected expired cookie to be rejected, got %v", err)
+	}
+}
+
+func TestImportSCS(t *testing.T) {
+	ss := sessions.New[string](&sessions.Requirements[string]{FallbackKeys: []string{"session"}})
+	deadline := time.Now().Add(time.Hour).Round(0)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&scsData{Deadline: deadline, Values: map[string]any{"user": "bob"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	row := SCSRow{Token: "scs-token", Data: buf.Bytes(), Expiry: deadline}
+	convert := func(values map[string]any) (string, error) {
+		user, _ := values["user"].(string)
+		return user, nil
+	}
+
+	if _, err := ImportSCS(ss, row, convert); err != nil {
+		t.Fatalf("Expected scs row to be imported, got %s", err)
+	}
+
+	s := ss.Get("scs-token")
+	if s == nil || s.Value() != "bob" {
+		t.Fatalf("Expected imported session to be found under the scs token")
+	}
+
+	if got := s.(sessions.Expirer).ExpiresAt(); !got.Equal(deadline) {
+		t.Errorf("Expected imported session to expire at %s, got %s", deadline, got)
+	}
+
+	if _, err := ImportSCS(ss, row, convert); !errors.Is(err, sessions.ErrExists) {
+		t.Errorf("Expected importing the same token twice to fail with ErrExists, got %v", err)
+	}
+
+	row.Token, row.Expiry = "expired", time.Now().Add(-time.Minute)
+	if _, err := ImportSCS(ss, row, convert); !errors.Is(err, sessions.ErrExpired) {
+		t.Errorf("Expected expired row to fail with ErrExpired, got %v", err)
+	}
+}
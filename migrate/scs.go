@@ -0,0 +1,64 @@
+package migrate
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/emillis/sessions"
+	"time"
+)
+
+//===========[STRUCTURES]===============================================================================================
+
+//SCSRow is a row of the sessions table used by the alexedwards/scs stores, e.g. postgresstore or mysqlstore
+type SCSRow struct {
+	//Token is the session token, the value of the scs cookie
+	Token string `json:"token" bson:"token"`
+
+	//Data is the session encoded by scs.GobCodec
+	Data []byte `json:"data" bson:"data"`
+
+	//Expiry is the time the row expires at
+	Expiry time.Time `json:"expiry" bson:"expiry"`
+}
+
+//Layout of the data encoded by scs.GobCodec
+type scsData struct {
+	Deadline time.Time
+	Values   map[string]any
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//DecodeSCS decodes session data encoded by scs.GobCodec, returning the deadline of the session and its values. Custom
+//types stored in the session must be registered with gob.Register, same as they were for scs
+func DecodeSCS(data []byte) (time.Time, map[string]any, error) {
+	var d scsData
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&d); err != nil {
+		return time.Time{}, nil, err
+	}
+
+	return d.Deadline, d.Values, nil
+}
+
+//ImportSCS decodes the scs row and adds it to the store under the scs token, expiring at the same time as the row
+//would have. convert turns the scs values into the value of the session. Since the token is kept, existing scs
+//cookies keep working if the scs cookie name is listed in Requirements.FallbackKeys and Requirements.Keys are not set
+func ImportSCS[TValue any](ss *sessions.SessionStore[TValue], row SCSRow, convert func(values map[string]any) (TValue, error)) (sessions.ISession[TValue], error) {
+	deadline, values, err := DecodeSCS(row.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	//The row might have been extended after the data was written, or cut short by the store
+	expiry := row.Expiry
+	if expiry.IsZero() || (!deadline.IsZero() && deadline.Before(expiry)) {
+		expiry = deadline
+	}
+
+	data, err := convert(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return ss.Import(row.Token, data, expiry)
+}
@@ -0,0 +1,179 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//MigrationBackend moves sessions from one Backend to another without downtime, e.g. from SQL to Redis. Every write
+//goes to both of them, so the old one stays complete and the move can be rolled back at any time. Reads prefer the
+//new backend and fall back to the old one, copying what they find there. Sessions that aren't read in the meantime
+//can be copied with Copy. Once Progress shows nothing is read from the old backend any more, it can be dropped
+type MigrationBackend[TValue any] struct {
+	//Writes go to both backends through the chain, the new one being the primary
+	chain *ChainBackend[TValue]
+
+	from, to Backend[TValue]
+
+	//OnError receives errors of copying sessions to the new backend. Such sessions keep being read from the old one
+	OnError func(err error)
+
+	writes   atomic.Uint64
+	readsNew atomic.Uint64
+	readsOld atomic.Uint64
+	copied   atomic.Uint64
+	failed   atomic.Uint64
+}
+
+//MigrationProgress is a point-in-time report of a MigrationBackend
+type MigrationProgress struct {
+	//Number of sessions written to both backends
+	Writes uint64 `json:"writes" bson:"writes"`
+
+	//Number of sessions read from the new backend and of the ones that were only found in the old one
+	ReadsNew uint64 `json:"reads_new" bson:"reads_new"`
+	ReadsOld uint64 `json:"reads_old" bson:"reads_old"`
+
+	//Number of sessions copied from the old backend to the new one, by reads or by Copy, and of the ones that failed
+	Copied uint64 `json:"copied" bson:"copied"`
+	Failed uint64 `json:"failed" bson:"failed"`
+}
+
+//Load returns the session from the new backend, or copies it there from the old one if it's not there yet
+func (m *MigrationBackend[TValue]) Load(key string) (TValue, uint64, error) {
+	value, version, old, err := m.load(key)
+	if err != nil {
+		return value, version, err
+	}
+
+	if old {
+		m.readsOld.Add(1)
+	} else {
+		m.readsNew.Add(1)
+	}
+
+	return value, version, nil
+}
+
+//Save writes the session to both backends. The version returned is the one of the new backend. Errors of either of
+//them are returned, so the old backend never falls behind
+func (m *MigrationBackend[TValue]) Save(s ISession[TValue], dirtyFields []string, expectedVersion uint64) (uint64, error) {
+	version, err := m.chain.Save(s, dirtyFields, expectedVersion)
+	if err == nil {
+		m.writes.Add(1)
+	}
+
+	return version, err
+}
+
+//Remove deletes the session from both backends
+func (m *MigrationBackend[TValue]) Remove(key string) error {
+	return m.chain.Remove(key)
+}
+
+//SessionExpired drops what the migration keeps about the session, see ExpiryListener
+func (m *MigrationBackend[TValue]) SessionExpired(key string) {
+	m.chain.SessionExpired(key)
+}
+
+//Copy copies the sessions stored under the keys to the new backend, unless they are there already, e.g. to finish the
+//migration of sessions that weren't read in the meantime. Keys usually come from listing the old backend. Returns the
+//number of sessions copied
+func (m *MigrationBackend[TValue]) Copy(keys []string) (int, error) {
+	var errs []error
+	n := 0
+
+	for _, key := range keys {
+		_, _, err := m.to.Load(key)
+		if err == nil {
+			continue
+		}
+
+		if errors.Is(err, ErrNotFound) {
+			var value TValue
+			var version uint64
+
+			if value, version, err = m.from.Load(key); errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if err == nil {
+				_, err = m.copy(key, value, version)
+			}
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sessions: copying %q: %w", key, err))
+			continue
+		}
+
+		n++
+	}
+
+	return n, errors.Join(errs...)
+}
+
+//Progress returns the counters of the migration collected so far
+func (m *MigrationBackend[TValue]) Progress() MigrationProgress {
+	return MigrationProgress{
+		Writes:   m.writes.Load(),
+		ReadsNew: m.readsNew.Load(),
+		ReadsOld: m.readsOld.Load(),
+		Copied:   m.copied.Load(),
+		Failed:   m.failed.Load(),
+	}
+}
+
+//Returns the session and whether it was found in the old backend only, in which case it's copied to the new one. The
+//version returned is the one of the new backend, 0 if the copy failed, so the next Save creates it there
+func (m *MigrationBackend[TValue]) load(key string) (TValue, uint64, bool, error) {
+	value, version, err := m.to.Load(key)
+	if !errors.Is(err, ErrNotFound) {
+		return value, version, false, err
+	}
+
+	value, version, err = m.from.Load(key)
+	if err != nil {
+		return value, 0, false, err
+	}
+
+	if version, err = m.copy(key, value, version); err != nil {
+		if m.OnError != nil {
+			m.OnError(fmt.Errorf("sessions: copying session to the new backend: %w", err))
+		}
+
+		return value, 0, true, nil
+	}
+
+	return value, version, true, nil
+}
+
+//Copies the value found in the old backend under the given version to the new one, returning the version of the new
+//one. Sessions copied by someone else in the meantime are left as they are
+func (m *MigrationBackend[TValue]) copy(key string, value TValue, oldVersion uint64) (uint64, error) {
+	m.chain.setVersion(key, 1, oldVersion)
+
+	version, err := m.to.Save(detachedAs(key, value), nil, 0)
+	if errors.Is(err, ErrVersionConflict) {
+		_, version, err = m.to.Load(key)
+		return version, err
+	}
+	if err != nil {
+		m.failed.Add(1)
+		return 0, err
+	}
+
+	m.copied.Add(1)
+
+	return version, nil
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Migrate returns a Backend that moves sessions from the old backend to the new one while both are in use, see
+//MigrationBackend. Use it as Requirements.Backend for the duration of the move
+func Migrate[TValue any](from, to Backend[TValue]) *MigrationBackend[TValue] {
+	return &MigrationBackend[TValue]{chain: Chain[TValue](to, from), from: from, to: to}
+}
@@ -0,0 +1,52 @@
+package sessions
+
+import "testing"
+
+//===========[TESTING]====================================================================================================
+
+func TestMigrate(t *testing.T) {
+	from := &mapBackend[string]{records: map[string]string{"legacy": "old value", "idle": "idle value"}}
+	to := &mapBackend[string]{records: make(map[string]string)}
+	backend := Migrate[string](from, to)
+
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: backend})
+	s := ss.New("value")
+	if err := ss.FlushToBackend(); err != nil {
+		t.Fatal(err)
+	}
+
+	if from.records[s.Uid()] != "value" || to.records[s.Uid()] != "value" {
+		t.Errorf("Expected writes to reach both backends")
+	}
+
+	if v, _, err := backend.Load("legacy"); err != nil || v != "old value" {
+		t.Errorf("Expected fallback to the old backend, got \"%s\", %v", v, err)
+	}
+
+	if to.records["legacy"] != "old value" {
+		t.Errorf("Expected session read from the old backend to be copied to the new one")
+	}
+
+	if _, _, err := backend.Load(s.Uid()); err != nil {
+		t.Errorf("Expected session to be read from the new backend, got %s", err)
+	}
+
+	if n, err := backend.Copy([]string{"legacy", "idle", "missing"}); err != nil || n != 1 {
+		t.Errorf("Expected 1 session to be copied, got %d, %v", n, err)
+	}
+
+	want := MigrationProgress{Writes: 1, ReadsNew: 1, ReadsOld: 1, Copied: 2}
+	if got := backend.Progress(); got != want {
+		t.Errorf("Expected progress %+v, got %+v", want, got)
+	}
+
+	ss.Remove(s.Uid())
+	_ = ss.FlushToBackend()
+
+	if _, exist := from.records[s.Uid()]; exist {
+		t.Errorf("Expected removal to reach the old backend")
+	}
+	if _, exist := to.records[s.Uid()]; exist {
+		t.Errorf("Expected removal to reach the new backend")
+	}
+}
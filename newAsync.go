@@ -0,0 +1,104 @@
+package sessions
+
+import (
+	"context"
+	"sync"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//Session waiting to be created by NewAsync
+type newJob[TValue any] struct {
+	ctx    context.Context
+	data   TValue
+	result chan ISession[TValue]
+}
+
+//Sessions waiting to be created by the workers of NewAsync
+type newQueue[TValue any] struct {
+	pending []newJob[TValue]
+
+	//Number of workers running
+	workers int
+
+	mx sync.Mutex
+}
+
+//Returns the number of sessions waiting to be created
+func (q *newQueue[TValue]) depth() int {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+	return len(q.pending)
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//NewAsync does the same as New, but creates the session on a pool of up to Requirements.NewWorkers workers, so
+//handlers aren't held up by a slow UidChecker and can wait for the session with a timeout of their own. The session,
+//or nil if it couldn't be created, is delivered on the returned channel. Up to Requirements.NewQueueSize sessions can
+//wait for the workers, beyond that nil is delivered right away. Errors go to Requirements.OnError. Use
+//NewAsyncContext to have the session skipped once the handler gives up on it
+func (ss *SessionStore[TValue]) NewAsync(data TValue) <-chan ISession[TValue] {
+	ss.ready()
+
+	return ss.NewAsyncContext(context.Background(), data)
+}
+
+//NewAsyncContext does the same as NewAsync, but sessions whose context is done by the time a worker gets to them are
+//skipped and get nil, so the workers don't spend UidChecker round trips on sessions nobody waits for anymore
+func (ss *SessionStore[TValue]) NewAsyncContext(ctx context.Context, data TValue) <-chan ISession[TValue] {
+	ss.ready()
+
+	r := ss.req()
+	result := make(chan ISession[TValue], 1)
+	q := &ss.newQueue
+
+	q.mx.Lock()
+	if len(q.pending) >= r.NewQueueSize {
+		q.mx.Unlock()
+
+		ss.reportError(ErrQueueFull)
+		result <- nil
+
+		return result
+	}
+
+	q.pending = append(q.pending, newJob[TValue]{ctx: ctx, data: data, result: result})
+	start := q.workers < r.NewWorkers
+	if start {
+		q.workers++
+	}
+	q.mx.Unlock()
+
+	if start {
+		go ss.newWorker()
+	}
+
+	return result
+}
+
+//Creates queued sessions until the queue is empty, skipping those nobody waits for anymore
+func (ss *SessionStore[TValue]) newWorker() {
+	q := &ss.newQueue
+
+	for {
+		q.mx.Lock()
+		if len(q.pending) == 0 {
+			q.pending = nil
+			q.workers--
+			q.mx.Unlock()
+			return
+		}
+
+		job := q.pending[0]
+		q.pending = q.pending[1:]
+		q.mx.Unlock()
+
+		if job.ctx.Err() != nil {
+			job.result <- nil
+			continue
+		}
+
+		job.result <- ss.New(job.data)
+	}
+}
@@ -0,0 +1,99 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_NewAsync(t *testing.T) {
+	var mx sync.Mutex
+	running, peak := 0, 0
+	started, release := make(chan struct{}, 6), make(chan struct{})
+
+	ss := initializeSessionStore(0, &Requirements[string]{
+		NewWorkers: 2,
+		UidChecker: UidCheckerFunc(func(context.Context, string) (bool, error) {
+			mx.Lock()
+			running++
+			peak = max(peak, running)
+			mx.Unlock()
+
+			started <- struct{}{}
+			<-release
+
+			mx.Lock()
+			running--
+			mx.Unlock()
+
+			return false, nil
+		}),
+	})
+
+	results := make([]<-chan ISession[string], 6)
+	for i := range results {
+		results[i] = ss.NewAsync("value")
+	}
+
+	//Both workers are held by the checker, the rest of the jobs wait for them
+	<-started
+	<-started
+	close(release)
+
+	for _, result := range results {
+		if s := <-result; s == nil || ss.Get(s.Uid()) != s {
+			t.Errorf("Expected every queued session to be created")
+		}
+	}
+
+	if peak != 2 {
+		t.Errorf("Expected sessions to be created by 2 workers at once, got %d", peak)
+	}
+}
+
+func TestSessionStore_NewAsync_Queue(t *testing.T) {
+	var reported []error
+	started, release := make(chan struct{}, 3), make(chan struct{})
+
+	ss := initializeSessionStore(0, &Requirements[string]{
+		NewWorkers:   1,
+		NewQueueSize: 2,
+		OnError:      func(err error) { reported = append(reported, err) },
+		UidChecker: UidCheckerFunc(func(context.Context, string) (bool, error) {
+			started <- struct{}{}
+			<-release
+			return false, nil
+		}),
+	})
+
+	first := ss.NewAsync("first")
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	abandoned := ss.NewAsyncContext(ctx, "abandoned")
+	queued := ss.NewAsync("queued")
+
+	if s := <-ss.NewAsync("overflow"); s != nil || len(reported) != 1 || !errors.Is(reported[0], ErrQueueFull) {
+		t.Errorf("Expected nil and ErrQueueFull once the queue is full, got %v", reported)
+	}
+
+	cancel()
+	close(release)
+
+	if s := <-abandoned; s != nil {
+		t.Errorf("Expected the session with a cancelled context to be skipped")
+	}
+
+	for _, result := range []<-chan ISession[string]{first, queued} {
+		if s := <-result; s == nil {
+			t.Errorf("Expected the queued sessions to be created")
+		}
+	}
+
+	if n := ss.Stats().Active; n != 2 {
+		t.Errorf("Expected 2 sessions to be created, got %d", n)
+	}
+}
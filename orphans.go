@@ -0,0 +1,65 @@
+package sessions
+
+import (
+	"fmt"
+	"sort"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//OrphanReport lists the places where internal caches of the store drifted apart
+type OrphanReport struct {
+	//UIDs queued for flushing that are no longer present in the store, e.g. removed by timeout
+	ModifiedOnly []string `json:"modified_only" bson:"modified_only"`
+
+	//Cache keys pointing to sessions that should be stored under a different key, mapped to that key, e.g. after SetUid
+	MismatchedKeys map[string]string `json:"mismatched_keys" bson:"mismatched_keys"`
+}
+
+//Empty checks whether nothing drifted
+func (o *OrphanReport) Empty() bool {
+	return len(o.ModifiedOnly) == 0 && len(o.MismatchedKeys) == 0
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Orphans scans internal caches of the store and reports sessions that are no longer consistent between them
+func (ss *SessionStore[TValue]) Orphans() OrphanReport {
+	ss.ready()
+
+	report := OrphanReport{MismatchedKeys: make(map[string]string)}
+
+	sessions := ss._sessions.GetAll()
+
+	for key, s := range sessions {
+		if expected := s.StorageKey(); expected != key {
+			report.MismatchedKeys[key] = expected
+		}
+	}
+
+	for key, s := range ss._modifiedSessions.GetAll() {
+		if _, exist := sessions[key]; !exist {
+			report.ModifiedOnly = append(report.ModifiedOnly, key)
+			continue
+		}
+
+		if expected := s.StorageKey(); expected != key {
+			report.MismatchedKeys[key] = expected
+		}
+	}
+
+	sort.Strings(report.ModifiedOnly)
+
+	return report
+}
+
+//Checks the invariants when Requirements.Debug is set, reporting violations to Requirements.OnError
+func (ss *SessionStore[TValue]) debugCheck() {
+	if !ss.req().Debug {
+		return
+	}
+
+	if report := ss.Orphans(); !report.Empty() {
+		ss.reportError(fmt.Errorf("%w: %d modified-only sessions, %d mismatched keys", ErrInvariantViolation, len(report.ModifiedOnly), len(report.MismatchedKeys)))
+	}
+}
@@ -0,0 +1,38 @@
+package sessions
+
+import (
+	"errors"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_Orphans(t *testing.T) {
+	var reported []error
+	ss := initializeSessionStore(3, &Requirements[string]{
+		Debug:   true,
+		OnError: func(err error) { reported = append(reported, err) },
+	})
+
+	if report := ss.Orphans(); !report.Empty() {
+		t.Errorf("Expected no orphans in a fresh store, got %+v", report)
+	}
+
+	s := ss.New("value").(*Session[string])
+	oldUid := s.Uid()
+
+	//Simulates the UID changing behind the store's back
+	s.mx.Lock()
+	s.session.Uid = "drifted"
+	s.mx.Unlock()
+	ss.debugCheck()
+
+	report := ss.Orphans()
+	if report.MismatchedKeys[oldUid] != "drifted" {
+		t.Errorf("Expected key \"%s\" to be reported as mismatched, got %+v", oldUid, report)
+	}
+
+	if len(reported) == 0 || !errors.Is(reported[0], ErrInvariantViolation) {
+		t.Errorf("Expected ErrInvariantViolation to be reported in debug mode, got %v", reported)
+	}
+}
@@ -0,0 +1,61 @@
+package sessions
+
+import "strconv"
+
+//===========[CACHE/STATIC]=============================================================================================
+
+const (
+	//PriorityLow sessions are evicted before any other, e.g. guests
+	PriorityLow Priority = -1
+
+	//PriorityNormal is the priority sessions are created with
+	PriorityNormal Priority = 0
+
+	//PriorityHigh sessions are only evicted once there are no others left, e.g. paying customers or checkouts in
+	//progress
+	PriorityHigh Priority = 1
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//Priority decides the order sessions are evicted in when a quota runs out, see QuotaEvictOldest. Sessions of lower
+//priority go first, and the oldest of them first. Any value can be used, the constants are just the common ones
+type Priority int
+
+//String returns the name of the priority, or its number if it's not one of the constants
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	}
+
+	return strconv.Itoa(int(p))
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Priority returns the priority class of the session
+func (s *Session[TValue]) Priority() Priority {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.session.Priority
+}
+
+//SetPriority puts the session into the priority class, e.g. PriorityHigh once the user starts checking out. While the
+//store is read-only, the class is left as it was and ErrReadOnly goes to Requirements.OnError
+func (s *Session[TValue]) SetPriority(p Priority) {
+	if err := s.writable(); err != nil {
+		s.store.reportError(err)
+		return
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.session.Priority = p
+	s.requeue()
+	s.touch()
+}
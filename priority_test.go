@@ -0,0 +1,69 @@
+package sessions
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSession_Priority(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{Quota: Quota{MaxSessions: 3, Policy: QuotaEvictOldest}})
+	clk := clockOf(ss)
+
+	vip := ss.New("vip").(*Session[string])
+	vip.SetPriority(PriorityHigh)
+	clk.Advance(time.Millisecond)
+	guest := ss.New("guest").(*Session[string])
+	clk.Advance(time.Millisecond)
+	user := ss.New("user").(*Session[string])
+	before := ss.Snapshot()
+	key := guest.StorageKey()
+
+	if got := before.Sessions[vip.StorageKey()].Metadata.Priority; got != PriorityHigh {
+		t.Errorf("Expected priority in the snapshot, got %s", got)
+	}
+
+	guest.SetPriority(PriorityLow)
+	if fields := Diff(before, ss.Snapshot()).Changed[key]; !slices.Contains(fields, "Priority") {
+		t.Errorf("Expected priority change in the diff, got %v", fields)
+	}
+
+	//Guest goes first despite being younger, then the normal ones, and the high priority one last
+	for _, evicted := range []*Session[string]{guest, user} {
+		clk.Advance(time.Millisecond)
+		ss.New("new").(*Session[string]).SetPriority(PriorityHigh)
+		if ss.Exist(evicted.Uid()) || !ss.Exist(vip.Uid()) {
+			t.Fatalf("Expected \"%s\" to be evicted while \"vip\" is kept", evicted.Value())
+		}
+	}
+
+	ss.New("new")
+	if ss.Exist(vip.Uid()) {
+		t.Errorf("Expected the oldest high priority session to go once there are no others")
+	}
+
+	var buf bytes.Buffer
+	old := initializeSessionStore(0, nil)
+	old.New("vip").(*Session[string]).SetPriority(PriorityHigh)
+	if _, err := old.WriteHandoff(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := initializeSessionStore(0, nil)
+	if _, err := restored.ReadHandoff(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range restored.Search(Query{}) {
+		if p := s.(Describer).Priority(); p != PriorityHigh {
+			t.Errorf("Expected priority to be handed over, got %s", p)
+		}
+	}
+
+	if PriorityHigh.String() != "high" || Priority(5).String() != "5" {
+		t.Errorf("Expected priorities to be named")
+	}
+}
@@ -0,0 +1,394 @@
+package sessions
+
+import (
+	"container/heap"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+const (
+	//QuotaReject refuses new sessions with ErrQuotaExceeded once the quota is used up
+	QuotaReject QuotaPolicy = iota
+
+	//QuotaEvictOldest removes the oldest sessions to make room for new ones, starting with those of the lowest Priority
+	QuotaEvictOldest
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//QuotaPolicy decides what happens when a new session doesn't fit in its quota
+type QuotaPolicy int
+
+//Quota limits sessions of a store, a tenant partition or a label. Limits are checked when sessions are created, so
+//values that grow afterwards can take a partition over its MaxBytes until the next session is created. 0 means no limit
+type Quota struct {
+	//MaxSessions is the most sessions there can be, hibernated ones included
+	MaxSessions int `json:"max_sessions" bson:"max_sessions"`
+
+	//MaxBytes is the most memory the values of the sessions can take, estimated by the size of their JSON encoding
+	MaxBytes int64 `json:"max_bytes" bson:"max_bytes"`
+
+	//Policy decides what happens when a new session doesn't fit. Defaults to QuotaReject
+	Policy QuotaPolicy `json:"policy" bson:"policy"`
+}
+
+//Usage of a quota
+type quotaUsage struct {
+	sessions int
+	bytes    int64
+}
+
+//Room taken in the quotas for a session before it's created
+type quotaReservation struct {
+	label string
+	size  int64
+}
+
+//Session in the eviction order of the quotas
+type quotaEntry[TValue any] struct {
+	s         *Session[TValue]
+	label     string
+	priority  Priority
+	createdAt time.Time
+
+	//Breaks ties between sessions created at the same time, older entries go first
+	seq uint64
+
+	//Positions in the order of the store and in the one of the label. -1 once the entry left the order
+	index [2]int
+}
+
+//Sessions in the order they are evicted in: lowest priority first, then oldest first. Each entry is in two orders at
+//once, slot tells which of its positions this one keeps
+type evictionOrder[TValue any] struct {
+	entries []*quotaEntry[TValue]
+	slot    int
+}
+
+func (o *evictionOrder[TValue]) Len() int { return len(o.entries) }
+func (o *evictionOrder[TValue]) Less(i, j int) bool {
+	a, b := o.entries[i], o.entries[j]
+	if a.priority != b.priority {
+		return a.priority < b.priority
+	}
+	if !a.createdAt.Equal(b.createdAt) {
+		return a.createdAt.Before(b.createdAt)
+	}
+	return a.seq < b.seq
+}
+func (o *evictionOrder[TValue]) Swap(i, j int) {
+	o.entries[i], o.entries[j] = o.entries[j], o.entries[i]
+	o.entries[i].index[o.slot] = i
+	o.entries[j].index[o.slot] = j
+}
+func (o *evictionOrder[TValue]) Push(x any) {
+	e := x.(*quotaEntry[TValue])
+	e.index[o.slot] = len(o.entries)
+	o.entries = append(o.entries, e)
+}
+func (o *evictionOrder[TValue]) Pop() any {
+	old := o.entries
+	e := old[len(old)-1]
+	o.entries = old[:len(old)-1]
+	e.index[o.slot] = -1
+	return e
+}
+
+//Usage of the quotas of the store and of its labels, along with the order sessions are evicted in
+type quotaTracker[TValue any] struct {
+	total   quotaUsage
+	byLabel map[string]quotaUsage
+
+	//Counted sessions in eviction order, all of them and by label
+	order        evictionOrder[TValue]
+	orderByLabel map[string]*evictionOrder[TValue]
+	seq          uint64
+
+	//Number of sessions refused or evicted because of a quota
+	rejected uint64
+	evicted  uint64
+
+	mx sync.Mutex
+}
+
+//Adds the difference to the usage of the store and of the label
+func (q *quotaTracker[TValue]) add(label string, sessions int, bytes int64) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	q.addLocked(label, sessions, bytes)
+}
+
+//Adds the difference to the usage of the store and of the label. The caller holds the lock
+func (q *quotaTracker[TValue]) addLocked(label string, sessions int, bytes int64) {
+	if q.byLabel == nil {
+		q.byLabel = make(map[string]quotaUsage)
+	}
+
+	q.total.sessions += sessions
+	q.total.bytes += bytes
+
+	u := q.byLabel[label]
+	u.sessions += sessions
+	u.bytes += bytes
+	q.byLabel[label] = u
+}
+
+//Returns the eviction order of the label. The caller holds the lock
+func (q *quotaTracker[TValue]) labelOrder(label string) *evictionOrder[TValue] {
+	if q.orderByLabel == nil {
+		q.orderByLabel = make(map[string]*evictionOrder[TValue])
+	}
+
+	o, exist := q.orderByLabel[label]
+	if !exist {
+		o = &evictionOrder[TValue]{slot: 1}
+		q.orderByLabel[label] = o
+	}
+
+	return o
+}
+
+//Puts the entry into the eviction orders. The caller holds the lock
+func (q *quotaTracker[TValue]) enqueue(e *quotaEntry[TValue]) {
+	q.seq++
+	e.seq = q.seq
+	heap.Push(&q.order, e)
+	heap.Push(q.labelOrder(e.label), e)
+}
+
+//Takes the entry out of the eviction orders, if it's still in them. The caller holds the lock
+func (q *quotaTracker[TValue]) dequeue(e *quotaEntry[TValue]) {
+	if i := e.index[0]; i >= 0 {
+		heap.Remove(&q.order, i)
+	}
+
+	o := q.labelOrder(e.label)
+	if i := e.index[1]; i >= 0 {
+		heap.Remove(o, i)
+	}
+
+	if o.Len() == 0 {
+		delete(q.orderByLabel, e.label)
+	}
+}
+
+//Moves the entry to its place after its priority or creation time changed
+func (q *quotaTracker[TValue]) reorder(e *quotaEntry[TValue], priority Priority, createdAt time.Time) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	e.priority, e.createdAt = priority, createdAt
+
+	if i := e.index[0]; i >= 0 {
+		heap.Fix(&q.order, i)
+	}
+	if i := e.index[1]; i >= 0 {
+		heap.Fix(q.labelOrder(e.label), i)
+	}
+}
+
+//Counts the reservation if it fits in the quotas. Otherwise, if the policy of the exceeded quota allows it, the next
+//session to evict is taken out of the eviction orders and returned, so no other reservation picks it as well. Returns
+//ErrQuotaExceeded if there is nothing to evict
+func (q *quotaTracker[TValue]) reserve(res *quotaReservation, quota Quota, labelQuota Quota, byLabel bool) (*Session[TValue], error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	var o *evictionOrder[TValue]
+
+	switch {
+	case !quota.fits(q.total, res.size):
+		if quota.Policy == QuotaEvictOldest {
+			o = &q.order
+		}
+	case byLabel && !labelQuota.fits(q.byLabel[res.label], res.size):
+		if labelQuota.Policy == QuotaEvictOldest {
+			o = q.labelOrder(res.label)
+		}
+	default:
+		q.addLocked(res.label, 1, res.size)
+		return nil, nil
+	}
+
+	if o == nil || o.Len() == 0 {
+		q.rejected++
+		return nil, ErrQuotaExceeded
+	}
+
+	e := o.entries[0]
+	q.dequeue(e)
+
+	return e.s, nil
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Checks whether any quota limits memory, in which case sizes of the values are tracked
+func (r *Requirements[TValue]) tracksBytes() bool {
+	if r.Quota.MaxBytes > 0 {
+		return true
+	}
+
+	for _, q := range r.LabelQuotas {
+		if q.MaxBytes > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+//Returns the estimated size of the value, if sizes are tracked
+func (ss *SessionStore[TValue]) valueSize(v TValue) int64 {
+	r := ss.req()
+	if !r.tracksBytes() {
+		return 0
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+
+	return int64(len(b))
+}
+
+//Checks whether one more session of the size fits in the quota with the usage
+func (q Quota) fits(u quotaUsage, size int64) bool {
+	return (q.MaxSessions <= 0 || u.sessions+1 <= q.MaxSessions) && (q.MaxBytes <= 0 || u.bytes+size <= q.MaxBytes)
+}
+
+//Starts counting the session against the quotas and puts it into the eviction order. The room reserved by admit is
+//taken over if there is one. The caller holds the session lock
+func (s *Session[TValue]) countQuota(reserved *quotaReservation) {
+	if s.session.counted || s.store == nil {
+		return
+	}
+
+	q := &s.store.quota
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	s.session.counted = true
+
+	if reserved != nil {
+		s.session.size = reserved.size
+	} else {
+		s.session.size = s.store.valueSize(s.session.Value)
+		q.addLocked(s.session.label, 1, s.session.size)
+	}
+
+	s.session.queued = &quotaEntry[TValue]{
+		s:         s,
+		label:     s.session.label,
+		priority:  s.session.Priority,
+		createdAt: s.session.CreatedAt,
+	}
+	q.enqueue(s.session.queued)
+}
+
+//Updates the place of the session in the eviction order after its priority or creation time was set. The caller
+//holds the session lock
+func (s *Session[TValue]) requeue() {
+	if e := s.session.queued; e != nil {
+		s.store.quota.reorder(e, s.session.Priority, s.session.CreatedAt)
+	}
+}
+
+//Updates the size of the value counted against the quotas. Hibernated sessions keep the size they were counted with.
+//The caller holds the session lock
+func (s *Session[TValue]) resizeQuota() {
+	if !s.session.counted || s.session.hibernated {
+		return
+	}
+
+	size := s.store.valueSize(s.session.Value)
+	s.store.quota.add(s.session.label, 0, size-s.session.size)
+	s.session.size = size
+}
+
+//Stops counting the session against the quotas, once it's gone from the store
+func (s *Session[TValue]) releaseQuota() {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if !s.session.counted {
+		return
+	}
+
+	q := &s.store.quota
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	s.session.counted = false
+	q.addLocked(s.session.label, -1, -s.session.size)
+	q.dequeue(s.session.queued)
+	s.session.queued = nil
+}
+
+//Reserves room for a new session with the value and label according to Requirements.Quota and
+//Requirements.LabelQuotas, evicting sessions if the policy allows it. The room is counted right away, so concurrent
+//calls can't fill the quotas over their limits together. It's taken over by newSession, or given back by
+//cancelReservation if the session isn't created after all. Returns ErrQuotaExceeded if the session doesn't fit
+func (ss *SessionStore[TValue]) admit(data TValue, label string) (*quotaReservation, error) {
+	r := ss.req()
+	res := &quotaReservation{label: label, size: ss.valueSize(data)}
+	labelQuota, byLabel := r.LabelQuotas[label]
+
+	for {
+		evicted, err := ss.quota.reserve(res, r.Quota, labelQuota, byLabel)
+		if err != nil {
+			return nil, err
+		}
+
+		if evicted == nil {
+			return res, nil
+		}
+
+		//A session that couldn't be evicted would be picked again and again
+		if !ss.evict(evicted) {
+			ss.quota.mx.Lock()
+			ss.quota.rejected++
+			ss.quota.mx.Unlock()
+
+			return nil, ErrQuotaExceeded
+		}
+
+		ss.quota.mx.Lock()
+		ss.quota.evicted++
+		ss.quota.mx.Unlock()
+	}
+}
+
+//Gives back the room reserved by admit for a session that wasn't created
+func (ss *SessionStore[TValue]) cancelReservation(res *quotaReservation) {
+	ss.quota.add(res.label, -1, -res.size)
+}
+
+//Removes the session taken out of the eviction order under the key it's cached under, which may still be the one
+//of a previous key. Returns false if that didn't free its room in the quotas
+func (ss *SessionStore[TValue]) evict(s *Session[TValue]) bool {
+	if key, ok := ss.cachedKey(s); ok {
+		ss.removeKey(key)
+	}
+
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	if !s.session.counted {
+		return true
+	}
+
+	//Still counted, so it goes back into the order to be evicted later
+	ss.quota.mx.Lock()
+	if e := s.session.queued; e != nil && e.index[0] < 0 {
+		ss.quota.enqueue(e)
+	}
+	ss.quota.mx.Unlock()
+
+	return false
+}
@@ -0,0 +1,98 @@
+package sessions
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestRequirements_Quota(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Quota:       Quota{MaxSessions: 2, Policy: QuotaEvictOldest},
+		LabelQuotas: map[string]Quota{"api": {MaxBytes: 10}},
+	})
+
+	clk := clockOf(ss)
+	first := ss.New("first")
+	clk.Advance(time.Millisecond)
+	ss.New("second")
+	clk.Advance(time.Millisecond)
+	ss.New("third")
+
+	if ss.Get(first.Uid()) != nil || ss.Stats().Active != 2 {
+		t.Errorf("Expected the oldest session to be evicted to make room")
+	}
+
+	if _, err := ss.newValidated("too long for the api quota", "api"); err != ErrQuotaExceeded {
+		t.Errorf("Expected a value over the label quota to be rejected, got %v", err)
+	}
+
+	if _, err := ss.newValidated("short", "api"); err != nil {
+		t.Errorf("Expected a value within the label quota to be accepted, got %v", err)
+	}
+
+	st := ss.Stats()
+	if st.QuotaEvicted != 2 || st.QuotaRejected != 1 || st.Labels["api"].Bytes != int64(len(`"short"`)) {
+		t.Errorf("Expected quota usage to be reported, got %+v", st)
+	}
+}
+
+func TestRequirements_Quota_Concurrent(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{Quota: Quota{MaxSessions: 10}})
+
+	var wg sync.WaitGroup
+	var created atomic.Int32
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := ss.NewE("value"); err == nil {
+				created.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := created.Load(); n != 10 || ss.Stats().Active != 10 {
+		t.Errorf("Expected exactly 10 sessions to be created concurrently, got %d", n)
+	}
+
+	evicting := initializeSessionStore(0, &Requirements[string]{Quota: Quota{MaxSessions: 10, Policy: QuotaEvictOldest}})
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			evicting.New("value")
+		}()
+	}
+	wg.Wait()
+
+	if st := evicting.Stats(); st.Active != 10 || st.QuotaEvicted != 90 {
+		t.Errorf("Expected 90 sessions to be evicted to keep 10, got %d active and %d evicted", st.Active, st.QuotaEvicted)
+	}
+}
+
+func TestRequirements_Quota_RotatedKeys(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Keys:  [][]byte{bytes.Repeat([]byte("a"), 32)},
+		Quota: Quota{MaxSessions: 1, Policy: QuotaEvictOldest},
+	})
+
+	old := ss.New("old")
+	if err := ss.RotateKeys(bytes.Repeat([]byte("b"), 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	//The old session is still cached under the key it was created with
+	if _, err := ss.NewE("new"); err != nil {
+		t.Fatalf("Expected the session under the previous key to be evicted, got %v", err)
+	}
+
+	if ss.Exist(old.Uid()) || ss.Stats().Active != 1 {
+		t.Errorf("Expected only the new session to be left")
+	}
+}
@@ -0,0 +1,32 @@
+package sessions
+
+import "golang.org/x/time/rate"
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Allow reports whether the action may happen now, consuming one token from the session's bucket for that action.
+//Buckets are kept by the session itself, not in Value, so per-user throttling of e.g. password attempts works for any
+//TValue. If the limit or burst of an existing bucket differs, the bucket is adjusted to the new values
+func (s *Session[TValue]) Allow(action string, limit rate.Limit, burst int) bool {
+	s.mx.Lock()
+	if s.session.limiters == nil {
+		s.session.limiters = make(map[string]*rate.Limiter)
+	}
+
+	l, exist := s.session.limiters[action]
+	if !exist {
+		l = rate.NewLimiter(limit, burst)
+		s.session.limiters[action] = l
+	}
+	s.mx.Unlock()
+
+	if l.Limit() != limit {
+		l.SetLimit(limit)
+	}
+
+	if l.Burst() != burst {
+		l.SetBurst(burst)
+	}
+
+	return l.Allow()
+}
@@ -0,0 +1,28 @@
+package sessions
+
+import (
+	"golang.org/x/time/rate"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSession_Allow(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	s := ss.New("value").(*Session[string])
+
+	for i := 0; i < 3; i++ {
+		if !s.Allow("login", rate.Every(time.Hour), 3) {
+			t.Errorf("Expected attempt %d to be allowed", i+1)
+		}
+	}
+
+	if s.Allow("login", rate.Every(time.Hour), 3) {
+		t.Errorf("Expected attempt 4 to be throttled")
+	}
+
+	if !s.Allow("search", rate.Every(time.Hour), 1) {
+		t.Errorf("Expected a different action to have its own bucket")
+	}
+}
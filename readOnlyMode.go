@@ -0,0 +1,44 @@
+package sessions
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//SetReadOnly switches maintenance mode on or off, e.g. for a blue/green cutover or a Backend migration. While it's on,
+//sessions can be read and keep timing out as usual, but creating, changing and removing them as well as writing to
+//the Backend or BlobStorage fails with ErrReadOnly. Operations without an error result report it to
+//Requirements.OnError instead. Tenant partitions of the store are read-only along with it
+func (ss *SessionStore[TValue]) SetReadOnly(readOnly bool) {
+	ss.ready()
+
+	ss.mx.Lock()
+	ss.readOnly = readOnly
+	ss.mx.Unlock()
+}
+
+//ReadOnly checks whether the store, or the store the partition belongs to, is in maintenance mode
+func (ss *SessionStore[TValue]) ReadOnly() bool {
+	ss.ready()
+
+	ss.mx.RLock()
+	readOnly := ss.readOnly
+	ss.mx.RUnlock()
+
+	return readOnly || (ss.parent != nil && ss.parent.ReadOnly())
+}
+
+//Returns ErrReadOnly if the store is in maintenance mode
+func (ss *SessionStore[TValue]) writable() error {
+	if ss.ReadOnly() {
+		return ErrReadOnly
+	}
+
+	return nil
+}
+
+//Returns ErrReadOnly if the session belongs to a store in maintenance mode
+func (s *Session[TValue]) writable() error {
+	if s.store == nil {
+		return nil
+	}
+
+	return s.store.writable()
+}
@@ -0,0 +1,92 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_SetReadOnly(t *testing.T) {
+	var reported []error
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Backend: newTestBackend(),
+		OnError: func(err error) { reported = append(reported, err) },
+	})
+	s := ss.New("value").(*Session[string])
+
+	ss.SetReadOnly(true)
+
+	if _, err := ss.NewE("new"); err != ErrReadOnly {
+		t.Errorf("Expected NewE to fail with ErrReadOnly, got %v", err)
+	}
+
+	if err := s.SetValueE("changed"); err != ErrReadOnly {
+		t.Errorf("Expected SetValueE to fail with ErrReadOnly, got %v", err)
+	}
+
+	if err := ss.FlushToBackend(); err != ErrReadOnly {
+		t.Errorf("Expected FlushToBackend to fail with ErrReadOnly, got %v", err)
+	}
+
+	ss.Remove(s.Uid())
+	if ss.Get(s.Uid()) != s || s.Value() != "value" {
+		t.Errorf("Expected the session to stay readable and unchanged")
+	}
+
+	if len(reported) != 1 || reported[0] != ErrReadOnly {
+		t.Errorf("Expected refused Remove to be reported, got %v", reported)
+	}
+
+	s.ExpireAt(clockOf(ss).Now())
+	if !ss.Exist(s.Uid()) || !s.ExpiresAt().IsZero() || len(reported) != 2 {
+		t.Errorf("Expected ExpireAt to be refused and reported, got %v", reported)
+	}
+
+	ss.SetReadOnly(false)
+	if err := s.SetValueE("changed"); err != nil {
+		t.Errorf("Expected writes to succeed after leaving read-only mode, got %v", err)
+	}
+}
+
+func TestSessionStore_SetReadOnly_Scopes(t *testing.T) {
+	var reported []error
+	ss := initializeSessionStore(0, &Requirements[string]{OnError: func(err error) { reported = append(reported, err) }})
+	s := ss.New("value").(*Session[string])
+
+	ss.SetReadOnly(true)
+
+	s.Scope("checkout").Set("step", 2)
+
+	if _, exist := s.Scope("checkout").Get("step"); exist {
+		t.Errorf("Expected scopes not to change while the store is read-only")
+	}
+
+	if len(reported) != 1 || reported[0] != ErrReadOnly {
+		t.Errorf("Expected refused changes to be reported, got %v", reported)
+	}
+}
+
+func TestSessionStore_SetReadOnly_Metadata(t *testing.T) {
+	var reported []error
+	ss := initializeSessionStore(0, &Requirements[string]{OnError: func(err error) { reported = append(reported, err) }})
+	s := ss.New("value").(*Session[string])
+	key, lastModified := s.Key(), s.LastModified()
+	release := s.SuspendExpiry()
+
+	ss.SetReadOnly(true)
+	clockOf(ss).Advance(time.Minute)
+
+	s.SetKey("other")
+	s.SetPriority(PriorityHigh)
+	s.UpdateLastModified()
+	release()
+
+	if s.Key() != key || s.Priority() != PriorityNormal || !s.LastModified().Equal(lastModified) {
+		t.Errorf("Expected the session to be left as it was while the store is read-only")
+	}
+
+	if len(reported) != 3 {
+		t.Errorf("Expected refused changes to be reported, got %v", reported)
+	}
+}
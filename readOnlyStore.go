@@ -0,0 +1,127 @@
+package sessions
+
+import (
+	"fmt"
+	"time"
+)
+
+//===========[INTERFACES]====================================================================================================
+
+//IReadOnlySession exposes only the getters of a session
+type IReadOnlySession[TValue any] interface {
+	Uid() string
+	Value() TValue
+	Key() string
+	LastModified() time.Time
+	CreatedAt() time.Time
+}
+
+//===========[STRUCTURES]===============================================================================================
+
+//ReadOnlySessionStore is a frozen copy of a SessionStore. It is disconnected from the live store, so iterating it
+//never blocks requests that are modifying the original sessions
+type ReadOnlySessionStore[TValue any] struct {
+	//Copies of the sessions at the time of cloning, keyed by UID rather than by storage key, so they can be looked up
+	//without the keys of the store
+	sessions map[string]*Session[TValue]
+
+	//Storage keys the sessions were cached under, by UID
+	storageKeys map[string]string
+
+	//Requirements.NormalizeUid of the store at the time of cloning
+	normalizeUid func(uid string) string
+
+	//Time at which the copy was made
+	createdAt time.Time
+}
+
+//Get returns a copy of the session based on the UID provided
+func (ro *ReadOnlySessionStore[TValue]) Get(uid string) IReadOnlySession[TValue] {
+	s, exist := ro.sessions[ro.uid(uid)]
+	if !exist {
+		return nil
+	}
+
+	return s
+}
+
+//Exist checks whether supplied uid existed in the store at the time of cloning
+func (ro *ReadOnlySessionStore[TValue]) Exist(uid string) bool {
+	_, exist := ro.sessions[ro.uid(uid)]
+	return exist
+}
+
+//Returns the uid as the store would normalize it
+func (ro *ReadOnlySessionStore[TValue]) uid(uid string) string {
+	if ro.normalizeUid == nil {
+		return uid
+	}
+
+	return ro.normalizeUid(uid)
+}
+
+//Count returns number of sessions in the copy
+func (ro *ReadOnlySessionStore[TValue]) Count() int {
+	return len(ro.sessions)
+}
+
+//ForEach runs the function supplied for every session in the copy
+func (ro *ReadOnlySessionStore[TValue]) ForEach(f func(IReadOnlySession[TValue])) {
+	for _, s := range ro.sessions {
+		f(s)
+	}
+}
+
+//CreatedAt returns time when the copy was made
+func (ro *ReadOnlySessionStore[TValue]) CreatedAt() time.Time {
+	return ro.createdAt
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//CloneReadOnly creates a frozen, read-only copy of the store. Sessions are copied one by one, so each of them is only
+//locked for the duration of its own copy. Values are copied shallowly, so pointers, slices and maps inside TValue are
+//still shared with the live sessions. Hibernated sessions are copied with their values read from the Backend, without
+//bringing them back into memory. Those whose values can't be read are left out and the error goes to
+//Requirements.OnError
+func (ss *SessionStore[TValue]) CloneReadOnly() *ReadOnlySessionStore[TValue] {
+	ss.ready()
+
+	all := ss._sessions.GetAll()
+	hibernated := ss._hibernated.GetAll()
+
+	ro := &ReadOnlySessionStore[TValue]{
+		sessions:     make(map[string]*Session[TValue], len(all)+len(hibernated)),
+		storageKeys:  make(map[string]string, len(all)+len(hibernated)),
+		normalizeUid: ss.req().NormalizeUid,
+		createdAt:    ss.now(),
+	}
+
+	for key, s := range all {
+		snapshot := s.snapshot()
+		ro.sessions[snapshot.session.Uid] = snapshot
+		ro.storageKeys[snapshot.session.Uid] = key
+	}
+
+	for key, s := range hibernated {
+		snapshot := s.snapshot()
+
+		s.mx.RLock()
+		asleep := s.session.hibernated
+		s.mx.RUnlock()
+
+		if asleep {
+			value, err := ss.hibernatedValue(key)
+			if err != nil {
+				ss.reportError(fmt.Errorf("sessions: copying hibernated session: %w", err))
+				continue
+			}
+			snapshot.session.Value = value
+		}
+
+		ro.sessions[snapshot.session.Uid] = snapshot
+		ro.storageKeys[snapshot.session.Uid] = key
+	}
+
+	return ro
+}
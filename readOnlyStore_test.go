@@ -0,0 +1,64 @@
+package sessions
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_CloneReadOnly(t *testing.T) {
+	ss := initializeSessionStore(5, nil)
+	s := ss.New("original")
+
+	ro := ss.CloneReadOnly()
+
+	s.SetValue("changed")
+	ss.New("added")
+
+	if ro.Count() != 6 {
+		t.Errorf("Expected the copy to contain 6 sessions, got %d", ro.Count())
+	}
+
+	if v := ro.Get(s.Uid()).Value(); v != "original" {
+		t.Errorf("Expected the copy to keep value \"original\", got \"%s\"", v)
+	}
+}
+
+func TestSessionStore_CloneReadOnly_Keys(t *testing.T) {
+	keyed := initializeSessionStore(0, &Requirements[string]{Keys: [][]byte{bytes.Repeat([]byte("k"), 32)}})
+	hashed := initializeSessionStore(0, &Requirements[string]{HashUid: func(uid string) string { return "hash:" + uid }})
+
+	for _, ss := range []*SessionStore[string]{keyed, hashed} {
+		s := ss.New("value")
+		ro := ss.CloneReadOnly()
+
+		if !ro.Exist(s.Uid()) || ro.Get(s.Uid()) == nil {
+			t.Errorf("Expected the copy to be looked up by UID when storage keys differ from UIDs")
+		}
+	}
+}
+
+func TestSessionStore_CloneReadOnly_Hibernated(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: newTestBackend(), HibernateAfter: time.Hour})
+	s := ss.New("value")
+
+	clockOf(ss).Advance(2 * time.Hour)
+	if err := ss.Hibernate(); err != nil {
+		t.Fatal(err)
+	}
+
+	ro := ss.CloneReadOnly()
+	if got := ro.Get(s.Uid()); got == nil || got.Value() != "value" {
+		t.Errorf("Expected the hibernated session to be copied with its value")
+	}
+
+	if st := ss.Stats(); st.Active != 0 || st.Hibernated != 1 {
+		t.Errorf("Expected the session to stay hibernated, got %+v", st)
+	}
+
+	if h := ss.IdleHistogram([]time.Duration{time.Hour}); h[">=1h0m0s"] != 1 {
+		t.Errorf("Expected the hibernated session to be counted, got %v", h)
+	}
+}
@@ -0,0 +1,223 @@
+package sessions
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//SessionRecord is the plain form of a session, meant for everything that exchanges sessions with the store from
+//outside, e.g. exporters, admin APIs or custom backends, so they all share the same shape. Convert sessions to and from
+//it with ToRecord and FromRecord
+type SessionRecord[TValue any] struct {
+	Uid          string         `json:"uid" bson:"uid"`
+	Key          string         `json:"key" bson:"key"`
+	Value        TValue         `json:"value" bson:"value"`
+	CreatedAt    time.Time      `json:"created_at" bson:"created_at"`
+	LastModified time.Time      `json:"last_modified" bson:"last_modified"`
+	ExpiresAt    time.Time      `json:"expires_at" bson:"expires_at"`
+	Metadata     RecordMetadata `json:"metadata" bson:"metadata"`
+
+	//Version of the session as last stored in the Backend. 0 means it was never stored
+	Version uint64 `json:"version" bson:"version"`
+}
+
+//RecordMetadata is what the store keeps about a session next to its value
+type RecordMetadata struct {
+	Label    string   `json:"label" bson:"label"`
+	Priority Priority `json:"priority" bson:"priority"`
+
+	//UID of the session this one was created by with NewChild, if any
+	Parent string `json:"parent" bson:"parent"`
+
+	//Attributes set by SetAttr. Once encoded, they come back with the types of the codec, e.g. float64 for numbers
+	//decoded from JSON, so AttrKey of other types reports them as not set
+	Attrs map[string]any `json:"attrs" bson:"attrs"`
+
+	//Whether the session is an API token created by NewToken, and the scopes it grants
+	Token       bool     `json:"token" bson:"token"`
+	TokenScopes []string `json:"token_scopes" bson:"token_scopes"`
+
+	//Names of the blobs attached with AttachBlob and the prefix they are kept under in Requirements.BlobStorage
+	Blobs      []string `json:"blobs" bson:"blobs"`
+	BlobPrefix string   `json:"blob_prefix" bson:"blob_prefix"`
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//ToRecord returns the record of the session. Sessions of other implementations give whatever their capabilities
+//expose, see Describer, Expirer, Attributer and Tokener
+func (ss *SessionStore[TValue]) ToRecord(s ISession[TValue]) SessionRecord[TValue] {
+	ss.ready()
+
+	sess, ok := s.(*Session[TValue])
+	if !ok {
+		return recordOf(s)
+	}
+
+	return sess.record()
+}
+
+//Returns the record of the session. If the value of a hibernated session can't be loaded back, the record carries the
+//zero value and the error goes to Requirements.OnError
+func (s *Session[TValue]) record() SessionRecord[TValue] {
+	if err := s.lockAwake(s.mx.RLock, s.mx.RUnlock); err != nil {
+		s.store.reportError(err)
+		s.mx.RLock()
+	}
+	rec := SessionRecord[TValue]{
+		Uid:          s.session.Uid,
+		Key:          s.session.Key,
+		Value:        s.session.Value,
+		CreatedAt:    s.session.CreatedAt,
+		LastModified: s.session.LastModified,
+		ExpiresAt:    s.session.ExpiresAt,
+		Metadata: RecordMetadata{
+			Label:       s.session.label,
+			Priority:    s.session.Priority,
+			Attrs:       maps.Clone(s.session.attrs),
+			Token:       s.session.token,
+			TokenScopes: slices.Clone(s.session.tokenScopes),
+			Blobs:       sortedFields(s.session.blobs),
+			BlobPrefix:  s.session.blobPrefix,
+		},
+		Version: s.session.version,
+	}
+	parent := s.session.parent
+	s.mx.RUnlock()
+
+	if parent != nil {
+		rec.Metadata.Parent = parent.Uid()
+	}
+
+	return rec
+}
+
+//Returns the record of a session of another implementation, filled in through its capabilities
+func recordOf[TValue any](s ISession[TValue]) SessionRecord[TValue] {
+	rec := SessionRecord[TValue]{
+		Uid:          s.Uid(),
+		Key:          s.Key(),
+		Value:        s.Value(),
+		LastModified: s.LastModified(),
+	}
+
+	if d, ok := s.(Describer); ok {
+		rec.CreatedAt = d.CreatedAt()
+		rec.Version = d.Version()
+		rec.Metadata.Label = d.Label()
+		rec.Metadata.Priority = d.Priority()
+	}
+
+	if e, ok := s.(Expirer); ok {
+		rec.ExpiresAt = e.ExpiresAt()
+	}
+
+	if a, ok := s.(Attributer); ok {
+		rec.Metadata.Attrs = a.Attrs()
+	}
+
+	if t, ok := s.(Tokener); ok {
+		rec.Metadata.Token = t.IsToken()
+		rec.Metadata.TokenScopes = t.TokenScopes()
+	}
+
+	if p, ok := s.(Spawner[TValue]); ok {
+		if parent := p.Parent(); parent != nil {
+			rec.Metadata.Parent = parent.Uid()
+		}
+	}
+
+	return rec
+}
+
+//FromRecord adds the session described by the record to the store, bypassing interceptors, the way Import does. The
+//UID is kept, an empty one means a new one is generated. Zero times mean now and an empty key means
+//Requirements.DefaultKey. Non-zero ExpiresAt becomes the absolute deadline of the session. The session is linked to
+//its parent if it's in the store. Returns ErrExists if the UID is already taken, ErrExpired if ExpiresAt has already
+//passed and ErrQuotaExceeded if the session doesn't fit Requirements.Quota or Requirements.LabelQuotas
+func (ss *SessionStore[TValue]) FromRecord(rec SessionRecord[TValue]) (ISession[TValue], error) {
+	ss.ready()
+
+	s, err := ss.addRecord(rec, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if rec.Metadata.Parent != "" {
+		ss.relink(map[string]string{s.session.Uid: rec.Metadata.Parent})
+	}
+
+	return s, nil
+}
+
+//Adds the session described by the record, see FromRecord, leaving the link to its parent to the caller. Whether the
+//UID is taken is checked against the store only, unless checkUid asks Requirements.UidChecker as well
+func (ss *SessionStore[TValue]) addRecord(rec SessionRecord[TValue], checkUid bool) (*Session[TValue], error) {
+	if err := ss.writable(); err != nil {
+		return nil, err
+	}
+
+	if !rec.ExpiresAt.IsZero() && !ss.now().Before(rec.ExpiresAt) {
+		return nil, ErrExpired
+	}
+
+	if err := ss.validateValue(rec.Value); err != nil {
+		return nil, err
+	}
+
+	if rec.Uid != "" && (ss.uidInStore(rec.Uid) || (checkUid && doesUidExist(ss, rec.Uid))) {
+		return nil, ErrExists
+	}
+
+	reserved, err := ss.admit(rec.Value, rec.Metadata.Label)
+	if err != nil {
+		return nil, err
+	}
+
+	uid := rec.Uid
+	if uid == "" {
+		if uid, err = generateUid(ss); err != nil {
+			ss.cancelReservation(reserved)
+			return nil, err
+		}
+	}
+
+	s := ss.newSession(uid, rec.Value, rec.Metadata.Label, reserved).(*Session[TValue])
+
+	s.mx.Lock()
+	if rec.Key != "" {
+		s.session.Key = rec.Key
+	}
+	if !rec.CreatedAt.IsZero() {
+		s.session.CreatedAt = rec.CreatedAt
+	}
+	if !rec.LastModified.IsZero() {
+		s.session.LastModified = rec.LastModified
+	}
+	s.session.ExpiresAt = rec.ExpiresAt
+	s.session.Priority = rec.Metadata.Priority
+	s.session.attrs = maps.Clone(rec.Metadata.Attrs)
+	s.session.token = rec.Metadata.Token
+	s.session.tokenScopes = slices.Clone(rec.Metadata.TokenScopes)
+	s.session.version = rec.Version
+	if len(rec.Metadata.Blobs) > 0 {
+		s.session.blobs = make(map[string]struct{}, len(rec.Metadata.Blobs))
+		for _, name := range rec.Metadata.Blobs {
+			s.session.blobs[name] = struct{}{}
+		}
+		s.session.blobPrefix = rec.Metadata.BlobPrefix
+	}
+	s.requeue()
+	s.mx.Unlock()
+
+	ss.armTimer(s)
+
+	if len(rec.Metadata.Blobs) > 0 && ss.req().BlobStorage != nil {
+		ss.trackBlobs(ss.storageKey(uid), s)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,78 @@
+package sessions
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_ToRecord(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+
+	parent := ss.NewLabeled("parent", "mobile").(*Session[string])
+	child, _ := parent.NewChild("child")
+	child.(*Session[string]).SetPriority(PriorityHigh)
+	child.(*Session[string]).SetAttr("csrf", "token")
+	child.(*Session[string]).ExpireAt(time.Now().Add(time.Hour))
+
+	rec := ss.ToRecord(child)
+	if rec.Uid != child.Uid() || rec.Value != "child" || rec.Metadata.Label != "mobile" || rec.Metadata.Parent != parent.Uid() {
+		t.Errorf("Expected the record to describe the session, got %+v", rec)
+	}
+
+	data, err := json.Marshal([]SessionRecord[string]{ss.ToRecord(parent), rec})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records []SessionRecord[string]
+	if err = json.Unmarshal(data, &records); err != nil {
+		t.Fatal(err)
+	}
+
+	other := initializeSessionStore(0, nil)
+	for _, r := range records {
+		if _, err = other.FromRecord(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := other.Get(child.Uid()).(*Session[string])
+	if got.Label() != "mobile" || got.Priority() != PriorityHigh || !got.ExpiresAt().Equal(rec.ExpiresAt) || !got.CreatedAt().Equal(rec.CreatedAt) {
+		t.Errorf("Expected the record to be restored")
+	}
+	if v, _ := got.GetAttr("csrf"); v != "token" {
+		t.Errorf("Expected attributes to be restored, got %v", v)
+	}
+	if p := got.Parent(); p == nil || p.Uid() != parent.Uid() {
+		t.Errorf("Expected the session to be linked to its parent")
+	}
+
+	if _, err = other.FromRecord(rec); err != ErrExists {
+		t.Errorf("Expected ErrExists, got %v", err)
+	}
+
+	rec.Uid, rec.ExpiresAt = "", time.Now().Add(-time.Second)
+	if _, err = other.FromRecord(rec); err != ErrExpired {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+}
+
+func TestSessionStore_FromRecordQuota(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{Quota: Quota{MaxSessions: 1}})
+
+	if _, err := ss.FromRecord(SessionRecord[string]{Value: "first"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ss.Import("", "second", time.Time{}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected records past the quota to be refused, got %v", err)
+	}
+
+	if st := ss.Stats(); st.Active != 1 || st.QuotaRejected != 1 {
+		t.Errorf("Expected the imported session to be counted against the quota, got %+v", st)
+	}
+}
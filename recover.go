@@ -0,0 +1,68 @@
+package sessions
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//PanicError is what a panic of a callback supplied in Requirements, such as UidChecker, ValidateValue, OnExpire or the
+//Backend, is turned into, see Requirements.PropagatePanics. Callers get it as an error where the callback returns one;
+//otherwise it goes to Requirements.OnError
+type PanicError struct {
+	//Callback names the callback that panicked, e.g. "ValidateValue"
+	Callback string
+
+	//Value is the value the callback panicked with
+	Value any
+
+	//Stack is the stack trace of the goroutine at the time of the panic
+	Stack []byte
+}
+
+//Error describes the panic
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("sessions: %s panicked: %v", e.Callback, e.Value)
+}
+
+//Unwrap returns the value the callback panicked with if it's an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Calls the callback, turning its panic into PanicError unless Requirements.PropagatePanics is set. The panic is
+//stopped right here, so locks held by the callers stay consistent. Callers holding a lock must still release it on
+//the way out, as a propagated panic unwinds past them
+func (ss *SessionStore[TValue]) protect(callback string, f func() error) error {
+	return protectWith(callback, ss.req().PropagatePanics, f)
+}
+
+//Calls the callback, turning its panic into PanicError unless propagate is set. It's protect for the callbacks of
+//types that have no store, such as ChecksumBackend
+func protectWith(callback string, propagate bool, f func() error) (err error) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+
+		if propagate {
+			panic(v)
+		}
+
+		err = &PanicError{Callback: callback, Value: v, Stack: debug.Stack()}
+	}()
+
+	return f()
+}
+
+//Calls the callback that has no error to return, reporting its panic to Requirements.OnError
+func (ss *SessionStore[TValue]) protectReport(callback string, f func()) {
+	if err := ss.protect(callback, func() error { f(); return nil }); err != nil {
+		ss.reportError(err)
+	}
+}
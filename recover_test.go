@@ -0,0 +1,147 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestRequirements_PropagatePanics(t *testing.T) {
+	var mx sync.Mutex
+	var reported []string
+	onError := func(err error) {
+		var p *PanicError
+		if errors.As(err, &p) {
+			mx.Lock()
+			reported = append(reported, p.Callback)
+			mx.Unlock()
+		}
+	}
+
+	expired := make(chan int, 2)
+	calls := 0
+	backend := panickingBackend{newTestBackend()}
+
+	ss := initializeSessionStore(0, &Requirements[string]{
+		OnError: onError,
+		UidChecker: UidCheckerFunc(func(context.Context, string) (bool, error) {
+			panic("checker")
+		}),
+		ValidateValue: func(v string) error {
+			if v == "bad" {
+				panic("validator")
+			}
+			return nil
+		},
+		Differ: func(old, new string) []string {
+			if new == "differ" {
+				panic("differ")
+			}
+			return nil
+		},
+		OnExpire: func(s []ISession[string]) {
+			calls++
+			expired <- calls
+			panic("on expire")
+		},
+		Backend: backend,
+	})
+
+	s, err := ss.NewE("value")
+	if err != nil {
+		t.Fatalf("Expected panicking UidChecker to fall back, got %s", err)
+	}
+
+	var p *PanicError
+	if _, err = ss.NewE("bad"); !errors.As(err, &p) || p.Callback != "ValidateValue" || p.Value != "validator" || len(p.Stack) == 0 {
+		t.Errorf("Expected PanicError of ValidateValue, got %v", err)
+	}
+
+	if err = s.(*Session[string]).UpdateE(func(v *string) { panic("update") }); !errors.As(err, &p) || p.Callback != "Update" {
+		t.Errorf("Expected PanicError of Update, got %v", err)
+	}
+
+	s.SetValue("differ")
+	if s.Value() != "differ" {
+		t.Errorf("Expected value to be set despite Differ panicking, got \"%s\"", s.Value())
+	}
+
+	if err = ss.FlushToBackend(); !errors.As(err, &p) || p.Callback != "Backend.Save" {
+		t.Errorf("Expected PanicError of Backend.Save, got %v", err)
+	}
+	if ss.Stats().Modified == 0 {
+		t.Errorf("Expected session to stay modified after the backend panicked")
+	}
+
+	clk := clockOf(ss)
+	a, b := ss.New("a").(*Session[string]), ss.New("b").(*Session[string])
+	a.ExpireAt(clk.Now().Add(time.Millisecond))
+	clk.Advance(time.Millisecond)
+	<-expired
+	b.ExpireAt(clk.Now().Add(time.Millisecond))
+	clk.Advance(time.Millisecond)
+
+	select {
+	case <-expired:
+	case <-time.After(time.Second):
+		t.Errorf("Expected expired sessions to keep being delivered after OnExpire panicked")
+	}
+
+	//Batches are delivered one after another, so the panic of the first one is reported by now
+	mx.Lock()
+	for _, callback := range []string{"UidChecker", "Differ", "OnExpire"} {
+		if !slices.Contains(reported, callback) {
+			t.Errorf("Expected panic of %s to be reported, got %v", callback, reported)
+		}
+	}
+	mx.Unlock()
+
+	strict := initializeSessionStore(0, &Requirements[string]{
+		PropagatePanics: true,
+		ValidateValue:   func(string) error { panic("validator") },
+	})
+
+	defer func() {
+		if r := recover(); r != "validator" {
+			t.Errorf("Expected panic to propagate, got %v", r)
+		}
+	}()
+
+	_, _ = strict.NewE("value")
+}
+
+func TestRequirements_PropagatePanics_Unlocks(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{PropagatePanics: true})
+	s := ss.New("value").(*Session[string])
+
+	func() {
+		defer func() {
+			if r := recover(); r != "update" {
+				t.Errorf("Expected panic to propagate, got %v", r)
+			}
+		}()
+
+		_ = s.UpdateE(func(*string) { panic("update") })
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		s.SetValue("new")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected the session lock to be released after the panic propagated")
+	}
+
+	if v := s.Value(); v != "new" {
+		t.Errorf("Expected \"new\", got \"%s\"", v)
+	}
+}
@@ -1,38 +1,390 @@
 package sessions
 
-import "time"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
 
 //===========[CACHE/STATIC]=============================================================================================
 
 //If requirements are not supplied, this will be used as default fallback
-var defaultRequirements = Requirements{
-	DefaultKey: "_ssid",
-	Timeout:    0,
-	UidExist:   func(uid string) bool { return false },
+func defaultRequirements[TValue any]() Requirements[TValue] {
+	return Requirements[TValue]{
+		DefaultKey: "_ssid",
+		Timeout:    0,
+		UidChecker: UidCheckerFunc(func(context.Context, string) (bool, error) { return false, nil }),
+		Differ:     structDiffer[TValue],
+
+		MaxExpirySuspension: time.Hour,
+		BlobSweepInterval:   time.Minute,
+		MaxKeys:             2,
+		MaxUidAttempts:      10,
+		NewWorkers:          8,
+		NewQueueSize:        1024,
+		MaxTenants:          1024,
+		OnExpireBatchSize:   100,
+		MaxCheckpoints:      8,
+	}
 }
 
 //===========[STRUCTS]====================================================================================================
 
 //Requirements outline the base setup of a SessionStore
-type Requirements struct {
+type Requirements[TValue any] struct {
 	//Sessions are usually "key":"value" pairs and so, this would be the default "key" in the "key":"value" pair
 	DefaultKey string `json:"default_key" bson:"default_key"`
 
+	//FallbackKeys are legacy cookie names checked when there is no cookie named DefaultKey, e.g. while the cookie is
+	//being renamed. Sessions found through them have their cookie re-issued under DefaultKey
+	FallbackKeys []string `json:"fallback_keys" bson:"fallback_keys"`
+
+	//Cache selects the storage of the in-memory caches. It's only applied by New. Defaults to CacheBuiltin
+	Cache CacheImplementation `json:"cache" bson:"cache"`
+
+	//CookieOptions are applied to cookies set by SetHttpCookie when no cookie is supplied. Use EmbeddedCookieOptions
+	//for third-party contexts
+	CookieOptions *CookieOptions `json:"cookie_options" bson:"cookie_options"`
+
+	//ClearBadCookies makes GetFromRequest and Middleware expire malformed and duplicate session cookies on the
+	//response, so browsers stop sending them
+	ClearBadCookies bool `json:"clear_bad_cookies" bson:"clear_bad_cookies"`
+
+	//OnBadCookie is called with ErrMalformedCookie or ErrMultipleCookies whenever a bad session cookie is received.
+	//Spikes of them are often a sign of an attack
+	OnBadCookie func(err error)
+
 	//Timout defines amount of time after which the session gets automatically removed if UpdateLastModified() not called
 	Timeout time.Duration `json:"timeout" bson:"timeout"`
 
-	//Here you can define a function that would check for existence of the UID other than locally within SessionStore.
+	//TTL decides when sessions time out, e.g. FixedTTL, AbsoluteIdleTTL or a TTLFunc for business specific policies.
+	//If set, Timeout is ignored. Defaults to SlidingTTL of Timeout
+	TTL TTLStrategy
+
+	//Hard cap on how long SuspendExpiry can keep a session from timing out. When it's reached, the suspension is
+	//released as if the release function was called. Defaults to 1 hour
+	MaxExpirySuspension time.Duration `json:"max_expiry_suspension" bson:"max_expiry_suspension"`
+
+	//BlobStorage keeps artifacts attached to sessions with AttachBlob. Blobs are deleted together with their session
+	BlobStorage BlobStorage
+
+	//How often blobs of expired sessions are collected. Defaults to 1 minute
+	BlobSweepInterval time.Duration `json:"blob_sweep_interval" bson:"blob_sweep_interval"`
+
+	//If set, removed sessions leave a tombstone for this long. While the tombstone exists, the UID is considered
+	//revoked and won't be reused or loaded again. Leave it at 0 to disable tombstones
+	TombstoneTimeout time.Duration `json:"tombstone_timeout" bson:"tombstone_timeout"`
+
+	//HashUid turns the UID into the key sessions are stored under, both in memory and in the Backend. Incoming
+	//tokens are hashed before every lookup, so a leaked Backend doesn't hand out usable session cookies. Use
+	//SHA256UidHasher or supply your own, e.g. a keyed HMAC. Leave it nil to store sessions under raw UIDs
+	HashUid func(uid string) string
+
+	//NormalizeUid is applied to UIDs before every lookup and when sessions are created, e.g. to trim, lowercase or strip
+	//a prefix added by clients or proxies, so differently mangled tokens resolve to the same session. It must be
+	//idempotent. Normalizing may shorten generated UIDs or reduce their alphabet, so keep it as narrow as possible
+	NormalizeUid func(uid string) string
+
+	//Keys are secrets used to sign cookies and, unless HashUid is set, to hash storage keys with HMAC-SHA256. The
+	//first key is the primary one, the rest are only used to verify cookies and find sessions stored before the keys
+	//were rotated. Use RotateKeys to add new keys. Leave empty to use unsigned cookies
+	Keys [][]byte `json:"-" bson:"-"`
+
+	//How many keys RotateKeys keeps, including the primary one. Defaults to 2
+	MaxKeys int `json:"max_keys" bson:"max_keys"`
+
+	//Here you can define a checker for existence of the UID other than locally within SessionStore.
 	//For example, check for existence in the Database or other caches
-	UidExist func(string) bool
+	UidChecker UidChecker
+
+	//How long a single UidChecker call may take. 0 means no timeout
+	UidCheckTimeout time.Duration `json:"uid_check_timeout" bson:"uid_check_timeout"`
+
+	//Decides what happens when UidChecker fails or times out. Defaults to UidCheckAssumeUnique
+	UidCheckFallback UidCheckFallback `json:"uid_check_fallback" bson:"uid_check_fallback"`
+
+	//LazyUidCheck skips UidChecker when sessions are created, cutting the latency of New. UIDs are long and random, so
+	//collisions are practically impossible; if one happens anyway, it's detected when the session is first saved to
+	//the Backend, and the session gets a new UID. Collisions are counted in Stats.UidCollisions
+	LazyUidCheck bool `json:"lazy_uid_check" bson:"lazy_uid_check"`
+
+	//How many UIDs are generated for a session before giving up with ErrUidExhausted, e.g. because UidChecker
+	//reports every UID as taken. Defaults to 10
+	MaxUidAttempts int `json:"max_uid_attempts" bson:"max_uid_attempts"`
+
+	//How many sessions NewAsync creates concurrently, which is also the most UidChecker calls it makes at once.
+	//Defaults to 8
+	NewWorkers int `json:"new_workers" bson:"new_workers"`
+
+	//How many sessions can wait for the workers of NewAsync. Once the queue is full, NewAsync delivers nil right away
+	//and reports ErrQueueFull. Defaults to 1024
+	NewQueueSize int `json:"new_queue_size" bson:"new_queue_size"`
+
+	//Debug enables internal invariant checks after operations that could make internal caches drift. Violations are
+	//reported as ErrInvariantViolation to OnError. The checks scan the whole store, so keep it off in production
+	Debug bool `json:"debug" bson:"debug"`
+
+	//OnError receives errors that can't be returned to the caller, e.g. failed UID existence checks
+	OnError func(err error)
+
+	//PropagatePanics lets panics of callbacks supplied here through, e.g. to crash early in tests. By default they are
+	//recovered and turned into PanicError, so one bad callback can't take down the goroutine serving the request or
+	//expiring sessions. HashUid and NormalizeUid decide where sessions are stored, so they are never recovered, and
+	//neither are Interceptors, which run as part of the call they wrap
+	PropagatePanics bool `json:"propagate_panics" bson:"propagate_panics"`
+
+	//Differ returns names of the fields that differ between the old and the new value. It is used to track which
+	//fields need to be written on the next Flush. By default, exported fields of struct values are compared
+	Differ func(old, new TValue) []string
+
+	//Backend is a persistent storage shared between nodes, such as a database. Modified sessions are written to it
+	//by FlushToBackend
+	Backend Backend[TValue]
+
+	//Sessions idle for longer than this have their values moved to the Backend, so only their metadata stays in
+	//memory. They are loaded back when requested. Requires Backend. Leave it at 0 to keep everything in memory
+	HibernateAfter time.Duration `json:"hibernate_after" bson:"hibernate_after"`
+
+	//ResolveConflict merges the local value with the one found in the Backend when another node has written the same
+	//session in the meantime. If not set, conflicts are returned as ErrVersionConflict
+	ResolveConflict func(local, remote TValue) TValue
+
+	//ValidateValue checks values before they enter the store through New, SetValue or Update. Rejected values are
+	//not stored; the error is returned wrapped in ValidationError by NewE, SetValueE and UpdateE
+	ValidateValue func(v TValue) error
+
+	//TenantOf picks the tenant a request belongs to, e.g. from the host name, so GetFromRequest and the middleware
+	//look the session up in the partition of the tenant only, see SessionStore.Tenant. Empty name means the store
+	//itself
+	TenantOf func(r *http.Request) string
+
+	//MaxTenants caps the number of partitions Tenant creates, as tenant names picked by TenantOf come from requests.
+	//Partitions are never dropped, so once the cap is reached, new tenants are refused with ErrTenantLimit. Defaults
+	//to 1024
+	MaxTenants int `json:"max_tenants" bson:"max_tenants"`
+
+	//Quota limits the sessions of the store. Tenant partitions get a quota of their own, see SessionStore.Tenant
+	Quota Quota `json:"quota" bson:"quota"`
+
+	//LabelQuotas limit sessions tagged with the label, on top of Quota
+	LabelQuotas map[string]Quota `json:"label_quotas" bson:"label_quotas"`
+
+	//UidFormat is checked against UIDs of incoming cookies and tokens before they are looked up. Anything goes by
+	//default, see GeneratedUidFormat
+	UidFormat UidFormat `json:"uid_format" bson:"uid_format"`
+
+	//JournalSize is how many of the latest operations on sessions are kept in memory for Journal. Leave it at 0 to
+	//disable the in-memory journal
+	JournalSize int `json:"journal_size" bson:"journal_size"`
+
+	//JournalWriter receives every journal entry as a line of JSON, e.g. to keep the journal in a file
+	JournalWriter io.Writer
+
+	//Interceptors wrap New, Get and SetValue calls. They are invoked in the order supplied, the first one being the
+	//outermost. This is the place for cross-cutting concerns such as validation or enrichment of values
+	Interceptors []Interceptor[TValue]
+
+	//OnExpire receives sessions removed by timeout, in batches of up to OnExpireBatchSize. Hibernated sessions are
+	//delivered without their values. It's called from a single goroutine, so batches never overlap. Requires
+	//CacheBuiltin
+	OnExpire func(expired []ISession[TValue])
+
+	//Maximum number of sessions passed to a single OnExpire call. Defaults to 100
+	OnExpireBatchSize int `json:"on_expire_batch_size" bson:"on_expire_batch_size"`
+
+	//Minimum time between OnExpire calls, so downstream systems aren't stampeded when many sessions expire at once.
+	//Sessions expiring in the meantime are queued, see Stats.ExpireQueue. 0 means no limit
+	OnExpireInterval time.Duration `json:"on_expire_interval" bson:"on_expire_interval"`
+
+	//How many checkpoints a session keeps. Taking more drops the oldest one. Defaults to 8
+	MaxCheckpoints int `json:"max_checkpoints" bson:"max_checkpoints"`
+
+	//Ephemeral sessions are never persisted: modified sessions are not tracked, so Flush and FlushToBackend have
+	//nothing to write. It can't be combined with Backend. See GuestRequirements
+	Ephemeral bool `json:"ephemeral" bson:"ephemeral"`
+
+	//Index extracts fields of the value the session can be looked up by, e.g. {"user": "42"}. Sessions expose them
+	//through the Indexer capability
+	Index func(v TValue) map[string]string
 }
 
 //===========[FUNCTIONALITY]====================================================================================================
 
+//Returns ErrInvalidRequirements wrapped with the description supplied
+func invalidRequirement(format string, a ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRequirements, fmt.Sprintf(format, a...))
+}
+
+//Validate checks the Requirements for values that would be silently adjusted or would make the store misbehave. Every
+//problem found is reported, each of them wrapping ErrInvalidRequirements. Zero values are valid, they mean defaults
+func (r *Requirements[TValue]) Validate() error {
+	var errs []error
+
+	if r.Timeout < 0 {
+		errs = append(errs, invalidRequirement("Timeout can't be negative, got %s", r.Timeout))
+	}
+
+	if r.TombstoneTimeout < 0 {
+		errs = append(errs, invalidRequirement("TombstoneTimeout can't be negative, got %s", r.TombstoneTimeout))
+	}
+
+	if r.MaxExpirySuspension < 0 {
+		errs = append(errs, invalidRequirement("MaxExpirySuspension can't be negative, got %s", r.MaxExpirySuspension))
+	}
+
+	if r.BlobSweepInterval < 0 {
+		errs = append(errs, invalidRequirement("BlobSweepInterval can't be negative, got %s", r.BlobSweepInterval))
+	}
+
+	if r.UidCheckTimeout < 0 {
+		errs = append(errs, invalidRequirement("UidCheckTimeout can't be negative, got %s", r.UidCheckTimeout))
+	}
+
+	if r.UidCheckFallback != UidCheckAssumeUnique && r.UidCheckFallback != UidCheckAssumeExists {
+		errs = append(errs, invalidRequirement("unknown UidCheckFallback %d", r.UidCheckFallback))
+	}
+
+	if r.HibernateAfter < 0 {
+		errs = append(errs, invalidRequirement("HibernateAfter can't be negative, got %s", r.HibernateAfter))
+	}
+
+	if r.Ephemeral && r.Backend != nil {
+		errs = append(errs, invalidRequirement("Ephemeral sessions can't be stored in a Backend"))
+	}
+
+	if r.HibernateAfter > 0 && r.Backend == nil {
+		errs = append(errs, invalidRequirement("HibernateAfter is set, but there is no Backend to hibernate to"))
+	}
+
+	if r.Cache != CacheBuiltin && r.Cache != CacheMachine {
+		errs = append(errs, invalidRequirement("unknown Cache %d", r.Cache))
+	}
+
+	if r.Cache == CacheMachine && r.OnExpire != nil {
+		errs = append(errs, invalidRequirement("OnExpire requires CacheBuiltin"))
+	}
+
+	if r.MaxCheckpoints < 0 {
+		errs = append(errs, invalidRequirement("MaxCheckpoints can't be negative, got %d", r.MaxCheckpoints))
+	}
+
+	if r.OnExpireBatchSize < 0 {
+		errs = append(errs, invalidRequirement("OnExpireBatchSize can't be negative, got %d", r.OnExpireBatchSize))
+	}
+
+	if r.OnExpireInterval < 0 {
+		errs = append(errs, invalidRequirement("OnExpireInterval can't be negative, got %s", r.OnExpireInterval))
+	}
+
+	if r.Cache == CacheMachine && !cacheMachineAvailable {
+		errs = append(errs, invalidRequirement("CacheMachine requires building with the sessions_cachemachine tag"))
+	}
+
+	if r.ResolveConflict != nil && r.Backend == nil {
+		errs = append(errs, invalidRequirement("ResolveConflict is set, but there is no Backend to conflict with"))
+	}
+
+	for i, key := range r.Keys {
+		if len(key) < minKeyLength {
+			errs = append(errs, invalidRequirement("Keys[%d] must be at least %d bytes long", i, minKeyLength))
+		}
+	}
+
+	if r.MaxKeys < 0 {
+		errs = append(errs, invalidRequirement("MaxKeys can't be negative, got %d", r.MaxKeys))
+	}
+
+	if r.MaxUidAttempts < 0 {
+		errs = append(errs, invalidRequirement("MaxUidAttempts can't be negative, got %d", r.MaxUidAttempts))
+	}
+
+	if r.JournalSize < 0 {
+		errs = append(errs, invalidRequirement("JournalSize can't be negative, got %d", r.JournalSize))
+	}
+
+	if r.NewWorkers < 0 {
+		errs = append(errs, invalidRequirement("NewWorkers can't be negative, got %d", r.NewWorkers))
+	}
+
+	if r.NewQueueSize < 0 {
+		errs = append(errs, invalidRequirement("NewQueueSize can't be negative, got %d", r.NewQueueSize))
+	}
+
+	if r.MaxTenants < 0 {
+		errs = append(errs, invalidRequirement("MaxTenants can't be negative, got %d", r.MaxTenants))
+	}
+
+	if r.Quota.MaxSessions < 0 || r.Quota.MaxBytes < 0 {
+		errs = append(errs, invalidRequirement("Quota limits can't be negative"))
+	}
+
+	for label, q := range r.LabelQuotas {
+		if q.MaxSessions < 0 || q.MaxBytes < 0 {
+			errs = append(errs, invalidRequirement("LabelQuotas[%q] limits can't be negative", label))
+		}
+	}
+
+	if f := r.UidFormat; f.MinLength < 0 || f.MaxLength < 0 || (f.MaxLength > 0 && f.MaxLength < f.MinLength) {
+		errs = append(errs, invalidRequirement("UidFormat length bounds are invalid, got %d to %d", f.MinLength, f.MaxLength))
+	}
+
+	if r.DefaultKey != "" && (&http.Cookie{Name: r.DefaultKey, Value: "v"}).Valid() != nil {
+		errs = append(errs, invalidRequirement("DefaultKey %q is not a valid cookie name", r.DefaultKey))
+	}
+
+	for _, k := range r.FallbackKeys {
+		if k == "" || (&http.Cookie{Name: k, Value: "v"}).Valid() != nil {
+			errs = append(errs, invalidRequirement("FallbackKeys entry %q is not a valid cookie name", k))
+		}
+	}
+
+	if r.CookieOptions != nil {
+		if err := r.CookieOptions.Validate(); err != nil {
+			errs = append(errs, invalidRequirement("%s", err))
+		}
+	}
+
+	errs = append(errs, r.validateCookiePrefix()...)
+
+	return errors.Join(errs...)
+}
+
+//Checks that cookie name prefixes recognized by browsers are backed by the required cookie attributes
+func (r *Requirements[TValue]) validateCookiePrefix() []error {
+	var errs []error
+
+	secure := r.CookieOptions != nil && r.CookieOptions.Secure
+
+	switch {
+	case strings.HasPrefix(r.DefaultKey, "__Secure-"):
+		if !secure {
+			errs = append(errs, invalidRequirement("cookies prefixed with __Secure- must be Secure"))
+		}
+	case strings.HasPrefix(r.DefaultKey, "__Host-"):
+		if !secure {
+			errs = append(errs, invalidRequirement("cookies prefixed with __Host- must be Secure"))
+		}
+		if r.CookieOptions == nil || r.CookieOptions.Path != "/" {
+			errs = append(errs, invalidRequirement("cookies prefixed with __Host- must have Path \"/\""))
+		}
+		if r.CookieOptions != nil && r.CookieOptions.Domain != "" {
+			errs = append(errs, invalidRequirement("cookies prefixed with __Host- can't have Domain"))
+		}
+	}
+
+	return errs
+}
+
 //Checks whether Requirements don't have problematic values
-func makeRequirementsReasonable(r *Requirements) *Requirements {
+func makeRequirementsReasonable[TValue any](r *Requirements[TValue]) *Requirements[TValue] {
+	defaultRequirements := defaultRequirements[TValue]()
+
 	if r == nil {
-		tmpReq := defaultRequirements
-		return &tmpReq
+		return &defaultRequirements
 	}
 
 	if r.DefaultKey == "" {
@@ -43,8 +395,56 @@ func makeRequirementsReasonable(r *Requirements) *Requirements {
 		r.Timeout = defaultRequirements.Timeout
 	}
 
-	if r.UidExist == nil {
-		r.UidExist = defaultRequirements.UidExist
+	if r.MaxExpirySuspension <= 0 {
+		r.MaxExpirySuspension = defaultRequirements.MaxExpirySuspension
+	}
+
+	if r.BlobSweepInterval <= 0 {
+		r.BlobSweepInterval = defaultRequirements.BlobSweepInterval
+	}
+
+	if r.MaxCheckpoints <= 0 {
+		r.MaxCheckpoints = defaultRequirements.MaxCheckpoints
+	}
+
+	if r.OnExpireBatchSize <= 0 {
+		r.OnExpireBatchSize = defaultRequirements.OnExpireBatchSize
+	}
+
+	if r.MaxKeys <= 0 {
+		r.MaxKeys = defaultRequirements.MaxKeys
+	}
+
+	if r.MaxUidAttempts <= 0 {
+		r.MaxUidAttempts = defaultRequirements.MaxUidAttempts
+	}
+
+	if r.NewWorkers <= 0 {
+		r.NewWorkers = defaultRequirements.NewWorkers
+	}
+
+	if r.NewQueueSize <= 0 {
+		r.NewQueueSize = defaultRequirements.NewQueueSize
+	}
+
+	if r.MaxTenants <= 0 {
+		r.MaxTenants = defaultRequirements.MaxTenants
+	}
+
+	if r.TombstoneTimeout < 0 {
+		r.TombstoneTimeout = defaultRequirements.TombstoneTimeout
+	}
+
+	if r.UidChecker == nil {
+		r.UidChecker = defaultRequirements.UidChecker
+	}
+
+	if r.UidCheckTimeout < 0 {
+		r.UidCheckTimeout = defaultRequirements.UidCheckTimeout
+	}
+
+	if r.Differ == nil {
+		r.Differ = defaultRequirements.Differ
 	}
 
 	return r
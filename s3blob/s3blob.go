@@ -0,0 +1,74 @@
+package s3blob
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/emillis/sessions"
+	"io"
+)
+
+//===========[INTERFACES]====================================================================================================
+
+//Client is the part of *s3.Client used by the Storage
+type Client interface {
+	PutObject(ctx context.Context, in *s3.PutObjectInput, opts ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, in *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, in *s3.DeleteObjectInput, opts ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+//===========[STRUCTURES]===============================================================================================
+
+//Storage keeps session blobs as objects in an S3 bucket. It implements sessions.BlobStorage
+type Storage struct {
+	//Client used to talk to S3, usually *s3.Client
+	Client Client
+
+	//Bucket where the objects are stored
+	Bucket string
+
+	//Prefix prepended to every object key, e.g. "sessions/"
+	Prefix string
+}
+
+//Put uploads the content as an object
+func (st *Storage) Put(ctx context.Context, key string, r io.Reader) error {
+	_, err := st.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(st.Bucket),
+		Key:    aws.String(st.Prefix + key),
+		Body:   r,
+	})
+
+	return err
+}
+
+//Get downloads the object. sessions.ErrBlobNotFound is returned if it doesn't exist
+func (st *Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := st.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(st.Bucket),
+		Key:    aws.String(st.Prefix + key),
+	})
+
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, sessions.ErrBlobNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Body, nil
+}
+
+//Delete removes the object. S3 doesn't fail on missing objects
+func (st *Storage) Delete(ctx context.Context, key string) error {
+	_, err := st.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(st.Bucket),
+		Key:    aws.String(st.Prefix + key),
+	})
+
+	return err
+}
@@ -0,0 +1,57 @@
+package s3blob
+
+import (
+	"bytes"
+	"context"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/emillis/sessions"
+	"io"
+	"strings"
+	"testing"
+)
+
+type testClient struct {
+	objects map[string][]byte
+}
+
+func (c *testClient) PutObject(_ context.Context, in *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	b, err := io.ReadAll(in.Body)
+	c.objects[*in.Bucket+"/"+*in.Key] = b
+	return &s3.PutObjectOutput{}, err
+}
+
+func (c *testClient) GetObject(_ context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	b, exist := c.objects[*in.Bucket+"/"+*in.Key]
+	if !exist {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(b))}, nil
+}
+
+func (c *testClient) DeleteObject(_ context.Context, in *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	delete(c.objects, *in.Bucket+"/"+*in.Key)
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+//===========[TESTING]====================================================================================================
+
+func TestStorage(t *testing.T) {
+	client := &testClient{objects: make(map[string][]byte)}
+	st := &Storage{Client: client, Bucket: "bucket", Prefix: "sessions/"}
+	ctx := context.Background()
+
+	if err := st.Put(ctx, "abc/file", strings.NewReader("content")); err != nil {
+		t.Fatalf("Expected Put to succeed, got %s", err)
+	}
+
+	if _, exist := client.objects["bucket/sessions/abc/file"]; !exist {
+		t.Errorf("Expected object to be stored under the prefix")
+	}
+
+	_ = st.Delete(ctx, "abc/file")
+
+	if _, err := st.Get(ctx, "abc/file"); err != sessions.ErrBlobNotFound {
+		t.Errorf("Expected ErrBlobNotFound for a deleted object, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package sessions
+
+import "sort"
+
+//===========[STRUCTS]====================================================================================================
+
+//Scope is a namespaced view of a session. Key:value pairs stored in one scope are isolated from every other scope
+//and from the session Value, so independent modules of an application can share one session without clashing
+type Scope[TValue any] struct {
+	//Name of the scope
+	name string
+
+	//Session this scope belongs to
+	s *Session[TValue]
+}
+
+//Name returns the name of this scope
+func (sc *Scope[TValue]) Name() string {
+	return sc.name
+}
+
+//Get returns value stored under the key in this scope and boolean depending on whether it exists
+func (sc *Scope[TValue]) Get(key string) (any, bool) {
+	sc.s.mx.RLock()
+	defer sc.s.mx.RUnlock()
+	v, exist := sc.s.session.scopes[sc.name][key]
+	return v, exist
+}
+
+//Set stores the value under the key in this scope
+func (sc *Scope[TValue]) Set(key string, v any) {
+	if err := sc.s.writable(); err != nil {
+		sc.s.store.reportError(err)
+		return
+	}
+
+	sc.s.mx.Lock()
+	if sc.s.session.scopes == nil {
+		sc.s.session.scopes = make(map[string]map[string]any)
+	}
+	if sc.s.session.scopes[sc.name] == nil {
+		sc.s.session.scopes[sc.name] = make(map[string]any)
+	}
+	sc.s.session.scopes[sc.name][key] = v
+	sc.s.touch()
+	sc.s.mx.Unlock()
+}
+
+//Delete removes the key from this scope
+func (sc *Scope[TValue]) Delete(key string) {
+	if err := sc.s.writable(); err != nil {
+		sc.s.store.reportError(err)
+		return
+	}
+
+	sc.s.mx.Lock()
+	delete(sc.s.session.scopes[sc.name], key)
+	sc.s.touch()
+	sc.s.mx.Unlock()
+}
+
+//Keys returns sorted keys present in this scope
+func (sc *Scope[TValue]) Keys() []string {
+	sc.s.mx.RLock()
+	defer sc.s.mx.RUnlock()
+
+	keys := make([]string, 0, len(sc.s.session.scopes[sc.name]))
+	for k := range sc.s.session.scopes[sc.name] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+//Clear removes every key from this scope
+func (sc *Scope[TValue]) Clear() {
+	if err := sc.s.writable(); err != nil {
+		sc.s.store.reportError(err)
+		return
+	}
+
+	sc.s.mx.Lock()
+	delete(sc.s.session.scopes, sc.name)
+	sc.s.touch()
+	sc.s.mx.Unlock()
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Scope returns a namespaced view of this session with the name supplied
+func (s *Session[TValue]) Scope(name string) *Scope[TValue] {
+	return &Scope[TValue]{name: name, s: s}
+}
@@ -0,0 +1,64 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSession_Scope(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	s := ss.New("value").(*Session[string])
+
+	checkout := s.Scope("checkout")
+	profile := s.Scope("profile")
+
+	checkout.Set("step", 2)
+	profile.Set("step", "avatar")
+
+	if v, _ := checkout.Get("step"); v != 2 {
+		t.Errorf("Expected \"step\" in checkout scope to be 2, got %v", v)
+	}
+
+	if v, _ := profile.Get("step"); v != "avatar" {
+		t.Errorf("Expected \"step\" in profile scope to be \"avatar\", got %v", v)
+	}
+
+	profile.Clear()
+
+	if _, exist := profile.Get("step"); exist {
+		t.Errorf("Expected profile scope to be empty after Clear")
+	}
+
+	if _, exist := s.Scope("checkout").Get("step"); !exist {
+		t.Errorf("Expected checkout scope to be unaffected by clearing profile scope")
+	}
+}
+
+func TestSession_Scope_Touch(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{Timeout: 100 * time.Millisecond})
+	s := ss.New("value").(*Session[string])
+
+	if err := ss.Flush(func(ISession[string], []string) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	clk := clockOf(ss)
+	clk.Advance(60 * time.Millisecond)
+	s.Scope("checkout").Set("step", 2)
+
+	flushed := 0
+	if err := ss.Flush(func(ISession[string], []string) error { flushed++; return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if flushed != 1 {
+		t.Errorf("Expected changing a scope to mark the session as modified")
+	}
+
+	clk.Advance(60 * time.Millisecond)
+	if ss.Get(s.Uid()) != s {
+		t.Errorf("Expected changing a scope to restart the idle timeout")
+	}
+}
@@ -0,0 +1,191 @@
+package sessions
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//Query selects sessions for Search. Every filter set must match; zero values are ignored
+type Query struct {
+	//Fields are equality filters on the fields extracted by Requirements.Index
+	Fields map[string]string `json:"fields" bson:"fields"`
+
+	//Sessions created within [CreatedAfter, CreatedBefore)
+	CreatedAfter  time.Time `json:"created_after" bson:"created_after"`
+	CreatedBefore time.Time `json:"created_before" bson:"created_before"`
+
+	//Sessions last modified within [ModifiedAfter, ModifiedBefore)
+	ModifiedAfter  time.Time `json:"modified_after" bson:"modified_after"`
+	ModifiedBefore time.Time `json:"modified_before" bson:"modified_before"`
+
+	//Label the sessions were tagged with at creation
+	Label string `json:"label" bson:"label"`
+
+	//Maximum number of sessions returned. 0 means no limit
+	Limit int `json:"limit" bson:"limit"`
+}
+
+//Inverted index of the fields extracted by Requirements.Index
+type sessionIndex[TValue any] struct {
+	//Sessions keyed by field name and then by field value
+	entries map[string]map[string]map[*Session[TValue]]struct{}
+
+	//Fields each session is currently indexed under, so they can be removed when the value changes
+	fields map[*Session[TValue]]map[string]string
+
+	mx sync.Mutex
+}
+
+//Indexes the session under the fields supplied, replacing the ones it was indexed under before
+func (ix *sessionIndex[TValue]) set(s *Session[TValue], fields map[string]string) {
+	ix.mx.Lock()
+	defer ix.mx.Unlock()
+
+	ix.removeLocked(s)
+
+	if len(fields) == 0 {
+		return
+	}
+
+	if ix.entries == nil {
+		ix.entries = make(map[string]map[string]map[*Session[TValue]]struct{})
+		ix.fields = make(map[*Session[TValue]]map[string]string)
+	}
+
+	for field, value := range fields {
+		if ix.entries[field] == nil {
+			ix.entries[field] = make(map[string]map[*Session[TValue]]struct{})
+		}
+		if ix.entries[field][value] == nil {
+			ix.entries[field][value] = make(map[*Session[TValue]]struct{})
+		}
+		ix.entries[field][value][s] = struct{}{}
+	}
+
+	ix.fields[s] = fields
+}
+
+//Removes the session from the index
+func (ix *sessionIndex[TValue]) remove(s *Session[TValue]) {
+	ix.mx.Lock()
+	ix.removeLocked(s)
+	ix.mx.Unlock()
+}
+
+//Same as remove, but the caller must hold the lock
+func (ix *sessionIndex[TValue]) removeLocked(s *Session[TValue]) {
+	for field, value := range ix.fields[s] {
+		delete(ix.entries[field][value], s)
+
+		if len(ix.entries[field][value]) == 0 {
+			delete(ix.entries[field], value)
+		}
+		if len(ix.entries[field]) == 0 {
+			delete(ix.entries, field)
+		}
+	}
+
+	delete(ix.fields, s)
+}
+
+//Returns sessions indexed under every one of the fields supplied
+func (ix *sessionIndex[TValue]) lookup(fields map[string]string) []*Session[TValue] {
+	ix.mx.Lock()
+	defer ix.mx.Unlock()
+
+	//Intersection starts from the smallest set
+	var smallest map[*Session[TValue]]struct{}
+	first := true
+	for field, value := range fields {
+		set := ix.entries[field][value]
+		if first || len(set) < len(smallest) {
+			smallest, first = set, false
+		}
+	}
+
+	var found []*Session[TValue]
+
+outer:
+	for s := range smallest {
+		for field, value := range fields {
+			if _, exist := ix.entries[field][value][s]; !exist {
+				continue outer
+			}
+		}
+		found = append(found, s)
+	}
+
+	return found
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Updates the index entries of the session after its value changed
+func (ss *SessionStore[TValue]) reindex(s *Session[TValue]) {
+	if ss.req().Index == nil {
+		return
+	}
+
+	ss.index.set(s, s.IndexFields())
+}
+
+//Search returns sessions matching the query, oldest first. Field filters are answered from the index kept for
+//Requirements.Index, so they don't scan the store; time ranges are then applied to the sessions found. Queries with
+//time ranges only scan every session. Hibernated sessions are searched as well, by the fields their value had when
+//they were hibernated
+func (ss *SessionStore[TValue]) Search(q Query) []ISession[TValue] {
+	ss.ready()
+
+	var candidates []*Session[TValue]
+
+	if len(q.Fields) > 0 {
+		for _, s := range ss.index.lookup(q.Fields) {
+			//Sessions that timed out are dropped from the index lazily
+			if !ss.holds(s.StorageKey(), s) {
+				ss.index.remove(s)
+				continue
+			}
+			candidates = append(candidates, s)
+		}
+	} else {
+		ss.forEachSession(func(_ string, s *Session[TValue]) {
+			candidates = append(candidates, s)
+		})
+	}
+
+	found := make([]*Session[TValue], 0, len(candidates))
+	for _, s := range candidates {
+		if q.Label != "" && s.Label() != q.Label {
+			continue
+		}
+
+		if inRange(s.CreatedAt(), q.CreatedAfter, q.CreatedBefore) && inRange(s.LastModified(), q.ModifiedAfter, q.ModifiedBefore) {
+			found = append(found, s)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].CreatedAt().Before(found[j].CreatedAt()) })
+
+	if q.Limit > 0 && len(found) > q.Limit {
+		found = found[:q.Limit]
+	}
+
+	result := make([]ISession[TValue], len(found))
+	for i, s := range found {
+		result[i] = s
+	}
+
+	return result
+}
+
+//Checks whether t is within [after, before). Zero bounds are open
+func inRange(t, after, before time.Time) bool {
+	if !after.IsZero() && t.Before(after) {
+		return false
+	}
+
+	return before.IsZero() || t.Before(before)
+}
@@ -0,0 +1,43 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_Search(t *testing.T) {
+	ss := initializeSessionStore(3, &Requirements[string]{
+		Index: func(v string) map[string]string { return map[string]string{"user": v} },
+	})
+
+	clk := clockOf(ss)
+	clk.Advance(time.Millisecond)
+	before := clk.Now()
+	clk.Advance(time.Millisecond)
+	first := ss.New("alice")
+	clk.Advance(time.Millisecond)
+	second := ss.New("alice")
+	clk.Advance(time.Millisecond)
+	bob := ss.New("bob")
+
+	if got := ss.Search(Query{Fields: map[string]string{"user": "alice"}}); len(got) != 2 || got[0] != first || got[1] != second {
+		t.Errorf("Expected both sessions of alice oldest first, got %v", got)
+	}
+
+	bob.SetValue("alice")
+	ss.Remove(first.Uid())
+
+	if got := ss.Search(Query{Fields: map[string]string{"user": "alice"}, Limit: 1}); len(got) != 1 || got[0] != second {
+		t.Errorf("Expected the index to follow value changes and removals, got %v", got)
+	}
+
+	if got := ss.Search(Query{Fields: map[string]string{"user": "bob"}}); len(got) != 0 {
+		t.Errorf("Expected no sessions of bob, got %v", got)
+	}
+
+	if got := ss.Search(Query{CreatedAfter: before}); len(got) != 2 {
+		t.Errorf("Expected 2 sessions created after the start of the test, got %d", len(got))
+	}
+}
@@ -1,7 +1,10 @@
 package sessions
 
 import (
+	"golang.org/x/time/rate"
+	"maps"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 )
@@ -22,14 +25,84 @@ type session[TValue any] struct {
 	//Holds the time when this session was modified last
 	LastModified time.Time `json:"last_modified" bson:"last_modified"`
 
+	//Holds the time when this session was created
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+
+	//If set, the session expires at this exact time regardless of Requirements.Timeout
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+
+	//Priority class deciding when the session is evicted, see Priority
+	Priority Priority `json:"priority" bson:"priority"`
+
+	//Token buckets used by Allow, keyed by action
+	limiters map[string]*rate.Limiter
+
+	//Names of blobs attached to the session and the random prefix they are stored under
+	blobs      map[string]struct{}
+	blobPrefix string
+
+	//Whether the cookie the session was last loaded from is signed with a previous key and should be re-issued
+	cookieStale bool
+
+	//Subscriptions created by Watch
+	watchers map[*watcher[TValue]]struct{}
+
+	//Number of active expiry suspensions. While it's above 0, the session doesn't time out
+	suspensions int
+
+	//Session this one was created by with NewChild and the ones created by this one. Ended is set once the session
+	//has left the store, so no more children are linked to it
+	parent   *Session[TValue]
+	children map[*Session[TValue]]struct{}
+	ended    bool
+
+	//Label the session was tagged with at creation, e.g. "mobile" or "api"
+	label string
+
+	//Whether the session is an API token created by NewToken and the scopes it grants
+	token       bool
+	tokenScopes []string
+
+	//Version of the session as last stored in the Backend. 0 means it was never stored
+	version uint64
+
+	//Counts changes of the session, so a flush can tell whether it changed while it was being written
+	modifications uint64
+
+	//Whether the value was moved out of memory by Hibernate. It has to be loaded back before it's used
+	hibernated bool
+
+	//Whether the session is counted against the quotas and the size of its value it's counted with
+	counted bool
+	size    int64
+
+	//Place of the session in the eviction order of the quotas, while it's counted
+	queued *quotaEntry[TValue]
+
+	//Values of namespaced sub-sessions, keyed by scope name and then by key
+	scopes map[string]map[string]any
+
+	//Names of the fields of Value that were changed since the last flush
+	dirtyFields map[string]struct{}
+
+	//Infrastructure attributes kept apart from Value, see SetAttr
+	attrs map[string]any
+
+	//Snapshots of Value taken by Checkpoint, oldest first, and the ID the next one gets
+	checkpoints    []checkpoint[TValue]
+	nextCheckpoint CheckpointID
+
 	store *SessionStore[TValue]
 
+	//Serializes flushes of this session, so its writes never overlap or reach the Backend out of order
+	flushMx sync.Mutex
+
 	mx sync.RWMutex
 }
 
 //Updates last modified field in this session, but this method is not protected by a mutex
 func (s *session[TValue]) updateLastModified() {
-	s.LastModified = time.Now()
+	s.LastModified = s.now()
 }
 
 //Session structure that defines an individual session
@@ -37,6 +110,36 @@ type Session[TValue any] struct {
 	session[TValue]
 }
 
+//Records a change of the session: updates LastModified, adds the session to the modified ones and restarts its
+//timeout according to Requirements.TTL. The caller holds the lock, so a concurrent flush either writes the change or
+//leaves the session modified. Sessions no longer in the store are not marked, so a flush can't bring them back
+func (s *Session[TValue]) touch() {
+	s.session.updateLastModified()
+	s.session.modifications++
+
+	ss := s.store
+	if ss == nil {
+		return
+	}
+
+	s.resizeQuota()
+
+	key := ss.storageKey(s.session.Uid)
+	if ss._sessions.GetValue(key) != s {
+		return
+	}
+
+	ss.markModified(key, s)
+
+	if !s.session.ExpiresAt.IsZero() || s.session.suspensions > 0 {
+		return
+	}
+
+	if d, ok := ss.timeLeft(s.session.CreatedAt, s.session.LastModified); ok {
+		ss._sessions.AddTimer(key, d)
+	}
+}
+
 //Uid returns unique ID of the session
 func (s *Session[TValue]) Uid() string {
 	s.mx.RLock()
@@ -44,27 +147,257 @@ func (s *Session[TValue]) Uid() string {
 	return s.session.Uid
 }
 
-//SetUid sets new uid for this session
+//SetUid sets new uid for this session and moves it under the uid in the store, so the old one stops working. UIDs
+//already in use are refused with ErrExists, which goes to Requirements.OnError. Prefer Regenerate, which picks a
+//random UID
 func (s *Session[TValue]) SetUid(uid string) {
-	s.mx.Lock()
-	defer s.mx.Unlock()
-	s.session.updateLastModified()
-	s.session.Uid = uid
+	if s.store == nil {
+		s.mx.Lock()
+		s.session.Uid = uid
+		s.touch()
+		s.mx.Unlock()
+
+		s.notify(ChangeRegenerated)
+		return
+	}
+
+	if err := s.store.writable(); err != nil {
+		s.store.reportError(err)
+		return
+	}
+
+	if uid == s.Uid() {
+		return
+	}
+
+	if doesUidExist(s.store, uid) {
+		s.store.reportError(ErrExists)
+		return
+	}
+
+	s.changeUid(uid)
+}
+
+//Moves the session under the uid, removing the record under the old one from the Backend. The old storage key stays
+//unusable for Requirements.TombstoneTimeout
+func (s *Session[TValue]) changeUid(uid string) {
+	old := s.StorageKey()
+	s.store.setUid(s, uid)
+	s.store.removeFromBackend(old)
+
+	if t := s.store.req().TombstoneTimeout; t > 0 {
+		s.store._tombstones.AddWithTimeout(old, struct{}{}, t)
+	}
+
+	s.notify(ChangeRegenerated)
+	s.store.debugCheck()
+}
+
+//Regenerate replaces the UID of this session with a newly generated one and moves the session under it, so the old
+//UID stops working. Call it whenever privileges change, e.g. on login, to prevent session fixation. Returns the new UID,
+//or the current one if it can't be changed, in which case the error goes to Requirements.OnError
+func (s *Session[TValue]) Regenerate() string {
+	uid, err := s.RegenerateE()
+	if err != nil && s.store != nil {
+		s.store.reportError(err)
+	}
+
+	return uid
+}
+
+//RegenerateE does the same as Regenerate, but returns the error along with the current UID if the UID can't be
+//changed, e.g. so a login can be refused rather than carried on under a UID that may have been planted
+func (s *Session[TValue]) RegenerateE() (string, error) {
+	if s.store == nil {
+		uid, err := randomUid()
+		if err != nil {
+			return s.Uid(), err
+		}
+		s.SetUid(uid)
+		return uid, nil
+	}
+
+	if err := s.store.writable(); err != nil {
+		return s.Uid(), err
+	}
+
+	uid, err := generateUid(s.store)
+	if err != nil {
+		return s.Uid(), err
+	}
+
+	s.changeUid(uid)
+
+	return uid, nil
+}
+
+//IndexFields returns fields of the value this session can be looked up by, as extracted by Requirements.Index
+func (s *Session[TValue]) IndexFields() map[string]string {
+	if s.store == nil {
+		return nil
+	}
+
+	index := s.store.req().Index
+	if index == nil {
+		return nil
+	}
+
+	var fields map[string]string
+	s.store.protectReport("Index", func() { fields = index(s.Value()) })
+
+	return fields
+}
+
+//RoutingKey returns key the session can be pinned to a node by, see RoutingKey
+func (s *Session[TValue]) RoutingKey() string {
+	return RoutingKey(s.Uid())
+}
+
+//StorageKey returns the key this session is stored under. It equals the UID, unless Requirements.HashUid is set, in
+//which case it's the hash of the UID. Backends must store sessions under this key
+func (s *Session[TValue]) StorageKey() string {
+	if s.store == nil {
+		return s.Uid()
+	}
+
+	return s.store.storageKey(s.Uid())
 }
 
-//Value returns value stored under this uid
+//Value returns value stored under this uid. The value of a hibernated session is loaded back from the Backend first.
+//If that fails, the zero value is returned and the error goes to Requirements.OnError
 func (s *Session[TValue]) Value() TValue {
-	s.mx.RLock()
+	if err := s.lockAwake(s.mx.RLock, s.mx.RUnlock); err != nil {
+		s.store.reportError(err)
+		var zero TValue
+		return zero
+	}
 	defer s.mx.RUnlock()
 	return s.session.Value
 }
 
-//SetValue assigns new value for the session
+//Assigns new value for the session, bypassing interceptors
+func (s *Session[TValue]) setValue(v TValue) error {
+	if err := s.lockAwake(s.mx.Lock, s.mx.Unlock); err != nil {
+		return err
+	}
+
+	//A propagated Differ panic unwinds past here, so the lock is released on the way out
+	unlocked := false
+	defer func() {
+		if !unlocked {
+			s.mx.Unlock()
+		}
+	}()
+
+	err := s.markDirty(s.session.Value, v)
+	s.session.Value = v
+	s.touch()
+	unlocked = true
+	s.mx.Unlock()
+
+	if s.store != nil {
+		if err != nil {
+			s.store.reportError(err)
+		}
+		s.store.reindex(s)
+	}
+
+	s.notify(ChangeValue)
+
+	return nil
+}
+
+//SetValue assigns new value for the session. Values rejected by Requirements.ValidateValue are not assigned and the
+//error goes to Requirements.OnError
 func (s *Session[TValue]) SetValue(v TValue) {
-	s.mx.Lock()
+	if err := s.SetValueE(v); err != nil {
+		s.store.reportError(err)
+	}
+}
+
+//SetValueE does the same as SetValue, but returns *ValidationError if the value is rejected by
+//Requirements.ValidateValue. The value is validated after it has passed through the interceptors
+func (s *Session[TValue]) SetValueE(v TValue) error {
+	if s.store == nil {
+		return s.setValue(v)
+	}
+
+	if err := s.store.writable(); err != nil {
+		return err
+	}
+
+	var err error
+	s.store.interceptSetValue(s, v, func(v TValue) {
+		if err = s.store.validateValue(v); err == nil {
+			err = s.setValue(v)
+		}
+	})
+
+	return err
+}
+
+//Update modifies the value in place while holding the session lock, so read-modify-write sequences can't race with
+//each other. Changed fields are recorded for the next flush. If the result is rejected by Requirements.ValidateValue,
+//the value is left as it was and the error goes to Requirements.OnError
+func (s *Session[TValue]) Update(f func(v *TValue)) {
+	if err := s.UpdateE(f); err != nil {
+		s.store.reportError(err)
+	}
+}
+
+//UpdateE does the same as Update, but returns *ValidationError if the result is rejected by
+//Requirements.ValidateValue, and *PanicError if the function panics. The function works on a copy of the value, so
+//keep in mind that maps, slices and pointers in it are shared with the current value
+func (s *Session[TValue]) UpdateE(f func(v *TValue)) error {
+	if s.store != nil {
+		if err := s.store.writable(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.lockAwake(s.mx.Lock, s.mx.Unlock); err != nil {
+		return err
+	}
+
+	//A propagated panic unwinds past here, so the lock is released on the way out
+	unlocked := false
+	defer func() {
+		if !unlocked {
+			s.mx.Unlock()
+		}
+	}()
+
+	old := s.session.Value
+	v := old
+
+	if s.store == nil {
+		f(&v)
+	} else {
+		err := s.store.protect("Update", func() error { f(&v); return nil })
+		if err == nil {
+			err = s.store.validateValue(v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
 	s.session.Value = v
-	s.session.updateLastModified()
+	err := s.markDirty(old, v)
+	s.touch()
+	unlocked = true
 	s.mx.Unlock()
+
+	s.notify(ChangeValue)
+
+	if s.store != nil {
+		if err != nil {
+			s.store.reportError(err)
+		}
+		s.store.reindex(s)
+	}
+
+	return nil
 }
 
 //Key returns session key that can be used as cookie name, etc..
@@ -74,26 +407,72 @@ func (s *Session[TValue]) Key() string {
 	return s.session.Key
 }
 
-//SetKey sets new key for this session
+//SetKey sets new key for this session. While the store is read-only, the key is left as it was and ErrReadOnly goes to
+//Requirements.OnError
 func (s *Session[TValue]) SetKey(k string) {
+	if err := s.writable(); err != nil {
+		s.store.reportError(err)
+		return
+	}
+
 	s.mx.Lock()
 	defer s.mx.Unlock()
-	s.session.updateLastModified()
 	s.session.Key = k
+	s.touch()
 }
 
-//SetHttpCookie sets cookie for the session in the ResponseWriter. The second cookie argument is optional and is used
-//to have some default values set by the client. In essence, this function would override the Name and Value fields of
-//the supplied cookie with the session values
-func (s *Session[TValue]) SetHttpCookie(w http.ResponseWriter, cookie *http.Cookie) {
+//Builds the cookie for this session on top of the cookie supplied. If it's nil, Requirements.CookieOptions of the store
+//are used
+func (s *Session[TValue]) httpCookie(cookie *http.Cookie) *http.Cookie {
 	if cookie == nil {
-		cookie = &http.Cookie{}
+		cookie = defaultCookie()
+
+		if s.store != nil {
+			cookie = s.store.baseCookie()
+		}
 	}
 
 	cookie.Name = s.Key()
 	cookie.Value = s.Uid()
 
-	http.SetCookie(w, cookie)
+	if s.store != nil {
+		cookie.Value = s.store.cookieValue(cookie.Value)
+	}
+
+	enforceCookieAttributes(cookie)
+
+	return cookie
+}
+
+//SetHttpCookie sets cookie for the session in the ResponseWriter. The second cookie argument is optional and is used
+//to have some default values set by the client. If it's not supplied, Requirements.CookieOptions of the store are used,
+//or a cookie with Path "/" if there are none, the same one ExpireHttpCookie deletes. In essence, this function would
+//override the Name and Value fields of the cookie with the session values. Cookies with SameSite=None or Partitioned
+//are always made Secure, as browsers reject them otherwise.
+//
+//Set-Cookie is a header and can't be sent as a trailer, so this must be called before the response body is written or
+//flushed. Headers added afterwards are silently dropped by net/http
+func (s *Session[TValue]) SetHttpCookie(w http.ResponseWriter, cookie *http.Cookie) {
+	http.SetCookie(w, s.httpCookie(cookie))
+
+	s.mx.Lock()
+	s.session.cookieStale = false
+	s.mx.Unlock()
+}
+
+//CookieStale checks whether the cookie this session was last loaded from is signed with a previous key, so it should
+//be re-issued with SetHttpCookie
+func (s *Session[TValue]) CookieStale() bool {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.session.cookieStale
+}
+
+//CookieHeaderValue returns the exact value of the Set-Cookie header for this session, built with
+//Requirements.CookieOptions of the store. It's meant for frameworks that manage headers themselves and have no
+//http.ResponseWriter. Empty string is returned if the cookie is invalid, e.g. the key is empty
+func (s *Session[TValue]) CookieHeaderValue() string {
+	return s.httpCookie(nil).String()
 }
 
 //LastModified returns time when this session was modified the last
@@ -103,10 +482,103 @@ func (s *Session[TValue]) LastModified() time.Time {
 	return s.session.LastModified
 }
 
-//UpdateLastModified Sets LastModified field to the time when this function gets invoked
+//CreatedAt returns time when this session was created
+func (s *Session[TValue]) CreatedAt() time.Time {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.session.CreatedAt
+}
+
+//ExpiresAt returns the absolute deadline of this session. Zero time means the session expires based on
+//Requirements.Timeout only
+func (s *Session[TValue]) ExpiresAt() time.Time {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.session.ExpiresAt
+}
+
+//ExpireAt overrides timeout based expiry with an absolute deadline, e.g. end of an exam window or a trial. Supplying
+//time in the past removes the session immediately. While the store is read-only, the deadline is left as it was and
+//ErrReadOnly goes to Requirements.OnError
+func (s *Session[TValue]) ExpireAt(t time.Time) {
+	if err := s.writable(); err != nil {
+		s.store.reportError(err)
+		return
+	}
+
+	s.mx.Lock()
+	s.session.ExpiresAt = t
+	s.touch()
+	uid := s.session.Uid
+	s.mx.Unlock()
+
+	if s.store == nil {
+		return
+	}
+
+	d := t.Sub(s.now())
+	if d <= 0 {
+		s.store.Remove(uid)
+		return
+	}
+
+	s.store._sessions.AddTimer(s.store.storageKey(uid), d)
+}
+
+//Checks whether the absolute deadline of the session has passed
+func (s *Session[TValue]) expired() bool {
+	t := s.ExpiresAt()
+	return !t.IsZero() && !s.now().Before(t)
+}
+
+//Label returns the label this session was tagged with at creation
+func (s *Session[TValue]) Label() string {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.session.label
+}
+
+//Version returns version of the session as last stored in the Backend. 0 means it was never stored
+func (s *Session[TValue]) Version() uint64 {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.session.version
+}
+
+//UpdateLastModified Sets LastModified field to the time when this function gets invoked, marks the session as
+//modified and restarts its idle timeout. While the store is read-only, nothing is changed and ErrReadOnly goes to
+//Requirements.OnError
 func (s *Session[TValue]) UpdateLastModified() {
+	if err := s.writable(); err != nil {
+		s.store.reportError(err)
+		return
+	}
+
 	s.mx.Lock()
-	s.session.updateLastModified()
+	s.touch()
 	s.mx.Unlock()
-	s.store._modifiedSessions.Add(s.Uid(), s)
+}
+
+//Creates a detached copy of this session. The copy does not belong to any store
+func (s *Session[TValue]) snapshot() *Session[TValue] {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	return &Session[TValue]{session[TValue]{
+		Uid:          s.session.Uid,
+		Key:          s.session.Key,
+		Value:        s.session.Value,
+		LastModified: s.session.LastModified,
+		CreatedAt:    s.session.CreatedAt,
+		ExpiresAt:    s.session.ExpiresAt,
+		Priority:     s.session.Priority,
+		label:        s.session.label,
+		mx:           sync.RWMutex{},
+		attrs:        maps.Clone(s.session.attrs),
+		token:        s.session.token,
+		tokenScopes:  slices.Clone(s.session.tokenScopes),
+		blobs:        maps.Clone(s.session.blobs),
+		blobPrefix:   s.session.blobPrefix,
+		version:      s.session.version,
+	}}
 }
@@ -1,8 +1,7 @@
 package sessions
 
 import (
-	"github.com/emillis/cacheMachine"
-	"github.com/emillis/idGen"
+	"fmt"
 	"net/http"
 	"sync"
 	"time"
@@ -14,6 +13,8 @@ type Cookie interface {
 	Cookie(string) (*http.Cookie, error)
 }
 
+//ISession is the minimal set of methods every session provides. Everything else is an optional capability, see
+//Expirer, Regenerator, Indexer and the rest
 type ISession[TValue any] interface {
 	Uid() string
 	SetUid(uid string)
@@ -30,117 +31,557 @@ type ISession[TValue any] interface {
 //Unexported session store where all the related sessions will be cached
 type sessionStore[TValue any] struct {
 	//Every pointer to a Session structure will be stored here
-	_sessions cacheMachine.Cache[string, *Session[TValue]]
+	_sessions cache[*Session[TValue]]
 
 	//Only purpose of this cache is to store pointers to Sessions that were modified. This cache is going to be used only
 	//for updating the database where instead of saving the entire cache, only the modified ones will be updated
-	_modifiedSessions cacheMachine.Cache[string, *Session[TValue]]
+	_modifiedSessions cache[*Session[TValue]]
 
 	//When checking for UID existence, possible unique ID will be stored here until determined that it's indeed unique
-	_tmpUidStore cacheMachine.Cache[string, struct{}]
+	_tmpUidStore cache[struct{}]
 
-	//DefaultKey is the default key used in key:value pairs such as cookie.Name
-	Requirements Requirements
+	//UIDs of removed sessions are kept here for Requirements.TombstoneTimeout, so they can't be resurrected
+	_tombstones cache[struct{}]
+
+	//Sessions that have blobs attached, so the blobs can be collected once the sessions are gone
+	_blobOwners cache[*Session[TValue]]
+
+	//Whether the periodic blob collection is scheduled
+	blobSweepRunning bool
+
+	//Sessions whose values were moved to the Backend after being idle. Only their metadata is kept here
+	_hibernated cache[*Session[TValue]]
+
+	//Whether the periodic hibernation is scheduled
+	hibernationRunning bool
+
+	//Whether Drain was called, after which no new sessions are issued
+	draining bool
+
+	//Source of time for timeouts and timestamps. Tests replace it before setup
+	clock clock
+
+	//Whether the store is in maintenance mode, see SetReadOnly
+	readOnly bool
+
+	//Counters reported by Stats
+	stats storeStats
+
+	//Usage of Requirements.Quota and Requirements.LabelQuotas
+	quota quotaTracker[TValue]
+
+	//Operations recorded for Journal
+	journal journal
+
+	//Expired sessions waiting to be passed to Requirements.OnExpire
+	expireQueue expireQueue[TValue]
+
+	//Sessions waiting to be created by NewAsync
+	newQueue newQueue[TValue]
+
+	//Sessions indexed by the fields extracted by Requirements.Index, used by Search
+	index sessionIndex[TValue]
+
+	//Partitions created by Tenant, keyed by tenant name
+	tenants map[string]*SessionStore[TValue]
+
+	//Store this partition belongs to and the name of its tenant. nil parent means the store isn't a partition
+	parent *SessionStore[TValue]
+	tenant string
+
+	//Setup of the store. It must only be changed through UpdateRequirements
+	Requirements Requirements[TValue]
+
+	//Initializes the zero value on first use, see ready
+	once sync.Once
 
 	mx sync.RWMutex
 }
 
-//SessionStore is exported access point to all the cached sessions
+//SessionStore is exported access point to all the cached sessions. The zero value is ready to use with default
+//Requirements, though New or MustNew are preferred
 type SessionStore[TValue any] struct {
 	sessionStore[TValue]
 }
 
-//New creates new session in this store with the Value supplied and returns pointer to it
-func (ss *SessionStore[TValue]) New(data TValue) ISession[TValue] {
-	uid := generateUid(ss)
+//Creates new session under the UID and adds it to the store, bypassing interceptors. The session takes over the room
+//reserved in the quotas, if there is one
+func (ss *SessionStore[TValue]) newSession(uid string, data TValue, label string, reserved *quotaReservation) ISession[TValue] {
+	r := ss.req()
+	uid = ss.normalizeUid(uid)
+	now := ss.now()
 
 	s := &Session[TValue]{session[TValue]{
-		Uid:   uid,
-		mx:    sync.RWMutex{},
-		store: ss,
-		Value: data,
+		Uid:          uid,
+		Key:          r.DefaultKey,
+		mx:           sync.RWMutex{},
+		store:        ss,
+		Value:        data,
+		LastModified: now,
+		CreatedAt:    now,
+		label:        label,
 	}}
 
-	ss._sessions.AddWithTimeout(uid, s, ss.Requirements.Timeout)
-	ss._modifiedSessions.Add(uid, s)
+	timeout, _ := ss.timeLeft(now, now)
+
+	key := ss.storageKey(uid)
+	ss._sessions.AddWithTimeout(key, s, timeout)
+
+	//Only sessions already in the cache get into the eviction order, so an eviction always finds them
+	s.mx.Lock()
+	s.countQuota(reserved)
+	s.mx.Unlock()
+
+	ss.markModified(key, s)
+	ss.stats.created(label)
+	ss.journalRecord(ChangeCreated, s)
+	ss.reindex(s)
+	ss.scheduleHibernation()
+	ss.debugCheck()
 
 	return s
 }
 
-//Get returns Session based on the UID provided
-func (ss *SessionStore[TValue]) Get(uid string) ISession[TValue] {
-	if e := ss._sessions.GetEntry(uid); e == nil {
+//Returns Session based on the UID provided, bypassing interceptors
+func (ss *SessionStore[TValue]) get(uid string) ISession[TValue] {
+	key := ss.storageKey(uid)
+
+	s, exist := ss._sessions.Get(key)
+	if !exist {
+		if s = ss.wake(key); s == nil {
+			if s = ss.rekey(uid); s == nil {
+				return nil
+			}
+		}
+	}
+
+	//The timer might not have fired yet, but the deadline has already passed
+	if s.expired() {
+		ss.remove(uid)
 		return nil
-	} else {
-		return e.Value()
 	}
+
+	return s
+}
+
+//Creates new session through the interceptors, validating the value right before the session is created
+func (ss *SessionStore[TValue]) newValidated(data TValue, label string) (ISession[TValue], error) {
+	if ss.isDraining() {
+		return nil, ErrDraining
+	}
+
+	if err := ss.writable(); err != nil {
+		return nil, err
+	}
+
+	var err error
+
+	s := ss.interceptNew(data, func(data TValue) ISession[TValue] {
+		if err = ss.validateValue(data); err != nil {
+			return nil
+		}
+
+		var reserved *quotaReservation
+		if reserved, err = ss.admit(data, label); err != nil {
+			return nil
+		}
+
+		var uid string
+		if uid, err = generateUid(ss); err != nil {
+			ss.cancelReservation(reserved)
+			return nil
+		}
+
+		return ss.newSession(uid, data, label, reserved)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
 }
 
-//GetFromCookie returns session if UID was specified in the http.Request cookies
+//New creates new session in this store with the Value supplied and returns pointer to it. If the value is rejected
+//by Requirements.ValidateValue, nil is returned and the error goes to Requirements.OnError
+func (ss *SessionStore[TValue]) New(data TValue) ISession[TValue] {
+	ss.ready()
+
+	s, err := ss.newValidated(data, "")
+	if err != nil {
+		ss.reportError(err)
+	}
+
+	return s
+}
+
+//NewE does the same as New, but returns *ValidationError if the value is rejected by Requirements.ValidateValue
+func (ss *SessionStore[TValue]) NewE(data TValue) (ISession[TValue], error) {
+	ss.ready()
+
+	return ss.newValidated(data, "")
+}
+
+//NewLabeled does the same as New, but also tags the session with a label, e.g. "mobile" or "api". Stats are reported
+//per label
+func (ss *SessionStore[TValue]) NewLabeled(data TValue, label string) ISession[TValue] {
+	ss.ready()
+
+	s, err := ss.newValidated(data, label)
+	if err != nil {
+		ss.reportError(err)
+	}
+
+	return s
+}
+
+//Get returns Session based on the UID provided
+func (ss *SessionStore[TValue]) Get(uid string) ISession[TValue] {
+	ss.ready()
+
+	return ss.interceptGet(uid, ss.get)
+}
+
+//GetFromCookie returns session if UID was specified in the http.Request cookies. Any problem with the cookie results
+//in nil; use GetFromRequest to tell them apart
 func (ss *SessionStore[TValue]) GetFromCookie(c Cookie) ISession[TValue] {
+	ss.ready()
+
 	if c == nil {
 		return nil
 	}
 
-	cookie, err := c.Cookie(ss.Requirements.DefaultKey)
+	r := ss.req()
+
+	for i, name := range append([]string{r.DefaultKey}, r.FallbackKeys...) {
+		cookie, err := c.Cookie(name)
+		if err != nil {
+			continue
+		}
+
+		s, err := ss.getByCookieValue(cookie.Value, i > 0)
+		if err == ErrMalformedCookie {
+			ss.badCookie(nil, name, err)
+		}
+
+		return s
+	}
+
+	return nil
+}
+
+//GetFromRequest returns the session the request cookie points to. Unlike GetFromCookie, it reports what went wrong:
+//http.ErrNoCookie if there is no session cookie, ErrMultipleCookies if there are several of them, ErrMalformedCookie if
+//it can't be parsed and ErrNotFound if the session doesn't exist. Bad cookies are reported to
+//Requirements.OnBadCookie and, if Requirements.ClearBadCookies is set, expired on w. Cookies found under one of
+//Requirements.FallbackKeys are moved to DefaultKey on w. With Requirements.TenantOf, the session is looked up in the
+//partition of the tenant, and the error of TenantE is returned if the tenant is refused. w may be nil
+func (ss *SessionStore[TValue]) GetFromRequest(w http.ResponseWriter, r *http.Request) (ISession[TValue], error) {
+	ss.ready()
+
+	if r == nil {
+		return nil, http.ErrNoCookie
+	}
+
+	t, err := ss.TenantForE(r)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
-	s, exist := ss._sessions.Get(cookie.Value)
-	if !exist {
-		return nil
+	if t != ss {
+		return t.GetFromRequest(w, r)
 	}
 
-	return s
+	req := ss.req()
+
+	for i, name := range append([]string{req.DefaultKey}, req.FallbackKeys...) {
+		cookies := r.CookiesNamed(name)
+		if len(cookies) == 0 {
+			continue
+		}
+
+		if len(cookies) > 1 {
+			ss.badCookie(w, name, ErrMultipleCookies)
+			return nil, ErrMultipleCookies
+		}
+
+		s, err := ss.getByCookieValue(cookies[0].Value, i > 0)
+		if err == ErrMalformedCookie {
+			ss.badCookie(w, name, err)
+		}
+
+		if c, ok := s.(CookieWriter); ok && i > 0 && w != nil {
+			ss.expireCookie(w, name)
+			c.SetHttpCookie(w, nil)
+		}
+
+		return s, err
+	}
+
+	return nil, http.ErrNoCookie
+}
+
+//Returns the session the cookie value points to. Sessions found through a cookie signed with a previous key or named
+//after one of Requirements.FallbackKeys are marked as having a stale cookie
+func (ss *SessionStore[TValue]) getByCookieValue(value string, legacy bool) (ISession[TValue], error) {
+	uid, stale, ok := ss.parseCookieValue(value)
+	if !ok {
+		return nil, ErrMalformedCookie
+	}
+
+	s := ss.Get(uid)
+	if s == nil {
+		return nil, ErrNotFound
+	}
+
+	//API tokens are never accepted from cookies
+	if sess, ok := s.(*Session[TValue]); ok && sess.IsToken() {
+		return nil, ErrNotFound
+	}
+
+	if sess, ok := s.(*Session[TValue]); ok && (stale || legacy) {
+		sess.mx.Lock()
+		sess.session.cookieStale = true
+		if legacy {
+			sess.session.Key = ss.req().DefaultKey
+		}
+		sess.mx.Unlock()
+	}
+
+	return s, nil
 }
 
 //Remove removes session based on the uid supplied
 func (ss *SessionStore[TValue]) Remove(uid string) {
-	ss._sessions.Remove(uid)
-	ss._modifiedSessions.Remove(uid)
+	ss.ready()
+
+	if err := ss.writable(); err != nil {
+		ss.reportError(err)
+		return
+	}
+
+	ss.remove(uid)
+}
+
+//Removes session based on the uid supplied, even if the store is read-only
+func (ss *SessionStore[TValue]) remove(uid string) {
+	key, exist := ss.lookupKey(uid)
+	if !exist {
+		key = ss.storageKey(uid)
+	}
+
+	ss.removeKey(key)
+}
+
+//Removes the session cached under the key, even if the store is read-only
+func (ss *SessionStore[TValue]) removeKey(key string) {
+	s, exist := ss._sessions.Get(key)
+	if !exist {
+		s, exist = ss._hibernated.Get(key)
+	}
+
+	if exist {
+		ss.stats.removed(s.Label())
+	}
+
+	ss._sessions.Remove(key)
+	ss._modifiedSessions.Remove(key)
+	ss._hibernated.Remove(key)
+
+	//Otherwise, the session could be loaded back from the Backend
+	if b := ss.req().Backend; exist && b != nil {
+		if err := ss.protect("Backend.Remove", func() error { return b.Remove(key) }); err != nil {
+			ss.reportError(fmt.Errorf("sessions: removing session from backend: %w", err))
+		}
+	}
+
+	if exist {
+		ss.index.remove(s)
+		s.releaseQuota()
+		s.notify(ChangeRemoved)
+		ss.endFamily(s, false)
+
+		if storage := ss.req().BlobStorage; storage != nil {
+			if err := s.deleteBlobs(storage); err != nil {
+				ss.reportError(fmt.Errorf("sessions: deleting blobs: %w", err))
+			}
+			ss._blobOwners.Remove(key)
+		}
+	}
+
+	if t := ss.req().TombstoneTimeout; t > 0 {
+		ss._tombstones.AddWithTimeout(key, struct{}{}, t)
+	}
+
+	ss.debugCheck()
+}
+
+//IsRevoked checks whether the session with supplied uid was removed recently and still has a tombstone
+func (ss *SessionStore[TValue]) IsRevoked(uid string) bool {
+	ss.ready()
+
+	for _, key := range append([]string{ss.storageKey(uid)}, ss.previousStorageKeys(uid)...) {
+		if ss._tombstones.Exist(key) {
+			return true
+		}
+	}
+
+	return false
 }
 
-//Exist checks whether supplied uid exist in the cache
+//Exist checks whether supplied uid exist in the cache, including hibernated sessions
 func (ss *SessionStore[TValue]) Exist(uid string) bool {
-	return ss._sessions.Exist(uid)
+	ss.ready()
+
+	_, exist := ss.lookupKey(uid)
+	return exist
+}
+
+//Makes the zero value of SessionStore usable by setting it up on first use the way New would, with whatever
+//Requirements were assigned to it beforehand. Calls on a nil store fail here with ErrNilStore instead of deep inside
+//the caches
+func (ss *SessionStore[TValue]) ready() {
+	if ss == nil {
+		panic(ErrNilStore)
+	}
+
+	ss.once.Do(func() {
+		if ss._sessions != nil {
+			return
+		}
+
+		ss.mx.Lock()
+		defer ss.mx.Unlock()
+
+		ss.setup(makeRequirementsReasonable(&ss.Requirements))
+	})
+}
+
+//Returns a copy of the Requirements currently in use, protected by the store lock
+func (ss *SessionStore[TValue]) req() Requirements[TValue] {
+	ss.mx.RLock()
+	defer ss.mx.RUnlock()
+	return ss.Requirements
+}
+
+//CurrentRequirements returns a copy of the Requirements currently in use. Unlike reading the Requirements field
+//directly, it's safe to call while UpdateRequirements is running
+func (ss *SessionStore[TValue]) CurrentRequirements() Requirements[TValue] {
+	ss.ready()
+
+	return ss.req()
+}
+
+//UpdateRequirements changes the setup of a running store without dropping its sessions. The function supplied
+//receives a copy of the current Requirements; if it ends up invalid, nothing is changed and the error is returned.
+//Changes apply to whatever happens next: new timeout affects new sessions and timers restarted from then on, cookie
+//options affect subsequent Set-Cookie headers, etc.
+func (ss *SessionStore[TValue]) UpdateRequirements(f func(r *Requirements[TValue])) error {
+	ss.ready()
+
+	ss.mx.Lock()
+	defer ss.mx.Unlock()
+
+	r := ss.Requirements
+	f(&r)
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	ss.Requirements = *makeRequirementsReasonable(&r)
+
+	return nil
 }
 
 //===========[FUNCTIONALITY]====================================================================================================
 
-//Generates and returns new unique UID
-func generateUid[TValue any](ss *SessionStore[TValue]) string {
-	for {
-		newUid := idGen.Random(&idGen.Config{Length: 99})
+//Generates and returns new unique UID. Gives up with ErrUidExhausted after Requirements.MaxUidAttempts
+func generateUid[TValue any](ss *SessionStore[TValue]) (string, error) {
+	format := ss.req().UidFormat
+
+	for attempt := 0; attempt < ss.req().MaxUidAttempts; attempt++ {
+		uid, err := randomUid()
+		if err != nil {
+			return "", err
+		}
+		newUid := format.seal(uid)
 
 		if doesUidExist(ss, newUid) {
+			ss.stats.collision()
 			continue
 		}
 
-		return newUid
+		return newUid, nil
 	}
+
+	ss.stats.exhausted()
+
+	return "", ErrUidExhausted
 }
 
 //doesUidExist checks the cache and db whether the uid already exist
 func doesUidExist[TValue any](ss *SessionStore[TValue], uid string) bool {
-	return ss._sessions.Exist(uid) || ss._tmpUidStore.Exist(uid) || ss.Requirements.UidExist(uid)
+	if ss.uidInStore(uid) {
+		return true
+	}
+
+	if ss.req().LazyUidCheck {
+		return false
+	}
+
+	return ss.checkUid(ss.storageKey(uid))
 }
 
-//New initiates and returns a pointer to SessionStore
-func New[TValue any](r *Requirements) *SessionStore[TValue] {
-	if r == nil {
-		r = &defaultRequirements
-	} else {
-		r = makeRequirementsReasonable(r)
+//Checks whether the uid is taken by a session of this store, reserved or revoked, without asking
+//Requirements.UidChecker
+func (ss *SessionStore[TValue]) uidInStore(uid string) bool {
+	_, exist := ss.lookupKey(uid)
+	return exist || ss._tmpUidStore.Exist(ss.storageKey(uid)) || ss.IsRevoked(uid)
+}
+
+//NewE validates the Requirements and initiates the SessionStore. Unlike New, it doesn't silently adjust problematic
+//values, but returns every problem found. nil Requirements are valid and mean defaults
+func NewE[TValue any](r *Requirements[TValue]) (*SessionStore[TValue], error) {
+	if r != nil {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
 	}
 
-	s := &SessionStore[TValue]{sessionStore[TValue]{
-		_sessions:         cacheMachine.New[string, *Session[TValue]](nil),
-		_modifiedSessions: cacheMachine.New[string, *Session[TValue]](nil),
-		_tmpUidStore:      cacheMachine.New[string, struct{}](nil),
-		Requirements:      *r,
-		mx:                sync.RWMutex{},
-	}}
+	return New[TValue](r), nil
+}
+
+//New initiates and returns a pointer to SessionStore
+func New[TValue any](r *Requirements[TValue]) *SessionStore[TValue] {
+	s := &SessionStore[TValue]{}
+	s.setup(makeRequirementsReasonable(r))
 
 	return s
 }
+
+//MustNew is like NewE, but panics if the Requirements are invalid. It's meant for stores set up at start-up, e.g. in
+//package level variables
+func MustNew[TValue any](r *Requirements[TValue]) *SessionStore[TValue] {
+	ss, err := NewE[TValue](r)
+	if err != nil {
+		panic(err)
+	}
+
+	return ss
+}
+
+//Creates the caches and sets the Requirements, which must already be reasonable
+func (ss *SessionStore[TValue]) setup(r *Requirements[TValue]) {
+	if ss.clock == nil {
+		ss.clock = systemClock{}
+	}
+
+	ss._modifiedSessions = newCache[*Session[TValue]](r.Cache, ss.clock, nil)
+	ss._tmpUidStore = newCache[struct{}](r.Cache, ss.clock, nil)
+	ss._tombstones = newCache[struct{}](r.Cache, ss.clock, nil)
+	ss._blobOwners = newCache[*Session[TValue]](r.Cache, ss.clock, nil)
+	ss._sessions = newCache[*Session[TValue]](r.Cache, ss.clock, ss.sessionExpired)
+	ss._hibernated = newCache[*Session[TValue]](r.Cache, ss.clock, ss.sessionExpired)
+	ss.Requirements = *r
+}
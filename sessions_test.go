@@ -1,33 +1,20 @@
 package sessions
 
 import (
+	"bytes"
+	"errors"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
-func initializeSessionStore(n int, r *Requirements) *SessionStore[string] {
-	s := New[string](r)
-
-	for ; n > 0; n-- {
-		s.New(string(rune(n)))
-	}
-
-	return s
-}
-
-type testHttpRequest struct {
-	cookie *http.Cookie
-}
-
-func (t *testHttpRequest) Cookie(s string) (*http.Cookie, error) {
-	return t.cookie, nil
-}
-
 //===========[TESTING]====================================================================================================
 
 func TestNew(t *testing.T) {
 	storeNoReq := New[string](nil)
-	storeWithReq := New[string](&Requirements{})
+	storeWithReq := New[string](&Requirements[string]{})
 
 	if storeNoReq == nil {
 		t.Errorf("Function New() with nil supplied for Requirements was expected to return a *SessionStore, got nil")
@@ -117,16 +104,152 @@ func TestSessionStore_GetFromCookie(t *testing.T) {
 	}
 }
 
+func TestSessionStore_GetFromRequest(t *testing.T) {
+	var reported []error
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Keys:            [][]byte{bytes.Repeat([]byte("k"), 32)},
+		ClearBadCookies: true,
+		OnBadCookie:     func(err error) { reported = append(reported, err) },
+	})
+	s := ss.New("value")
+
+	get := func(values ...string) (ISession[string], *httptest.ResponseRecorder, error) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		for _, v := range values {
+			r.AddCookie(&http.Cookie{Name: s.Key(), Value: v})
+		}
+		w := httptest.NewRecorder()
+		got, err := ss.GetFromRequest(w, r)
+		return got, w, err
+	}
+
+	if _, _, err := get(); err != http.ErrNoCookie {
+		t.Errorf("Expected http.ErrNoCookie without a cookie, got %v", err)
+	}
+
+	_, w, err := get(s.Uid())
+	if err != ErrMalformedCookie {
+		t.Errorf("Expected unsigned cookie to be malformed, got %v", err)
+	}
+	if c := w.Result().Cookies(); len(c) != 1 || c[0].MaxAge >= 0 {
+		t.Errorf("Expected malformed cookie to be cleared, got %v", c)
+	}
+
+	value := ss.cookieValue(s.Uid())
+	if _, _, err := get(value, value); err != ErrMultipleCookies {
+		t.Errorf("Expected ErrMultipleCookies, got %v", err)
+	}
+
+	if got, _, err := get(value); err != nil || got != s {
+		t.Errorf("Expected the session to be found, got %v", err)
+	}
+
+	if len(reported) != 2 {
+		t.Errorf("Expected 2 bad cookies to be reported, got %v", reported)
+	}
+}
+
+func TestRequirements_FallbackKeys(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{DefaultKey: "sid", FallbackKeys: []string{"_ssid"}})
+	s := ss.New("value")
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "_ssid", Value: s.Uid()})
+
+	if ss.GetFromCookie(r) != s {
+		t.Fatalf("Expected the session to be found through the legacy cookie")
+	}
+
+	w := httptest.NewRecorder()
+	if got, err := ss.GetFromRequest(w, r); err != nil || got != s {
+		t.Fatalf("Expected the session to be found through the legacy cookie, got %v", err)
+	}
+
+	cookies := map[string]*http.Cookie{}
+	for _, c := range w.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+
+	if c := cookies["sid"]; c == nil || c.Value != s.Uid() {
+		t.Errorf("Expected the cookie to be re-issued under DefaultKey")
+	}
+
+	if c := cookies["_ssid"]; c == nil || c.MaxAge >= 0 {
+		t.Errorf("Expected the legacy cookie to be expired")
+	}
+}
+
+func TestRequirements_ClearBadFallbackCookie(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{
+		DefaultKey:      "sid",
+		FallbackKeys:    []string{"_ssid"},
+		Keys:            [][]byte{bytes.Repeat([]byte("k"), 32)},
+		ClearBadCookies: true,
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "_ssid", Value: "unsigned"})
+
+	w := httptest.NewRecorder()
+	if _, err := ss.GetFromRequest(w, r); err != ErrMalformedCookie {
+		t.Fatalf("Expected the legacy cookie to be malformed, got %v", err)
+	}
+
+	if c := w.Result().Cookies(); len(c) != 1 || c[0].Name != "_ssid" || c[0].MaxAge >= 0 {
+		t.Errorf("Expected the malformed legacy cookie to be cleared, got %v", c)
+	}
+}
+
 func TestSession_SetUid(t *testing.T) {
 	ss := initializeSessionStore(0, nil)
 	s := ss.New("test_1")
 	newUid := "this_is_new_uid"
 
+	oldUid := s.Uid()
+
 	s.SetUid(newUid)
 
 	if s.Uid() != newUid {
 		t.Errorf("Expected the new UID to be \"%s\", got \"%s\"", newUid, s.Uid())
 	}
+
+	if ss.Get(newUid) != s || ss.Get(oldUid) != nil {
+		t.Errorf("Expected the session to be moved under the new UID")
+	}
+
+	other := ss.New("test_2")
+	other.SetUid(newUid)
+	if other.Uid() == newUid {
+		t.Errorf("Expected a UID in use to be refused")
+	}
+}
+
+func TestSession_SetValue_Touch(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{Timeout: 100 * time.Millisecond})
+	s := ss.New("value")
+
+	noop := func(ISession[string], []string) error { return nil }
+	if err := ss.Flush(noop); err != nil {
+		t.Fatal(err)
+	}
+
+	clk := clockOf(ss)
+	clk.Advance(60 * time.Millisecond)
+	s.SetValue("changed")
+
+	flushed := 0
+	if err := ss.Flush(func(ISession[string], []string) error { flushed++; return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if flushed != 1 {
+		t.Errorf("Expected SetValue to mark the session as modified")
+	}
+
+	clk.Advance(60 * time.Millisecond)
+	if ss.Get(s.Uid()) != s {
+		t.Errorf("Expected SetValue to restart the idle timeout")
+	}
 }
 
 func TestSession_SetKey(t *testing.T) {
@@ -140,3 +263,202 @@ func TestSession_SetKey(t *testing.T) {
 		t.Errorf("Key was expected to be \"%s\", got \"%s\"", newKey, s.Key())
 	}
 }
+
+func TestSessionStore_IsRevoked(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{TombstoneTimeout: time.Minute})
+	uid := ss.New("1").Uid()
+
+	if ss.IsRevoked(uid) {
+		t.Errorf("Session with UID \"%s\" shouldn't be revoked before removal", uid)
+	}
+
+	ss.Remove(uid)
+
+	if !ss.IsRevoked(uid) {
+		t.Errorf("Session with UID \"%s\" should be revoked after removal", uid)
+	}
+}
+
+func TestSessionStore_ZeroValue(t *testing.T) {
+	var ss SessionStore[string]
+	ss.Requirements.DefaultKey = "sid"
+
+	if ss.Exist("missing") || ss.Get("missing") != nil {
+		t.Errorf("Expected empty zero value store")
+	}
+
+	s := ss.New("value")
+	if got := ss.Get(s.Uid()); got != s {
+		t.Errorf("Expected session to be found in zero value store")
+	}
+
+	if ss.req().DefaultKey != "sid" {
+		t.Errorf("Expected Requirements assigned before first use to be kept, got \"%s\"", ss.req().DefaultKey)
+	}
+
+	if st := ss.Stats(); st.Active != 1 {
+		t.Errorf("Expected 1 session in stats, got %d", st.Active)
+	}
+
+	ss.Remove(s.Uid())
+	if ss.Exist(s.Uid()) {
+		t.Errorf("Expected session to be removed")
+	}
+
+	for name, f := range map[string]func(ss *SessionStore[string]){
+		"Stats":    func(ss *SessionStore[string]) { ss.Stats() },
+		"Snapshot": func(ss *SessionStore[string]) { ss.Snapshot() },
+		"Orphans":  func(ss *SessionStore[string]) { ss.Orphans() },
+		"Journal":  func(ss *SessionStore[string]) { ss.Journal(time.Time{}) },
+		"Search":   func(ss *SessionStore[string]) { ss.Search(Query{}) },
+		"Flush":    func(ss *SessionStore[string]) { _ = ss.Flush(func(ISession[string], []string) error { return nil }) },
+		"Tenant":   func(ss *SessionStore[string]) { ss.Tenant("acme").New("value") },
+		"NewToken": func(ss *SessionStore[string]) { _, _ = ss.NewToken("value", nil, time.Hour) },
+		"Import":   func(ss *SessionStore[string]) { _, _ = ss.Import("", "value", time.Time{}) },
+		"Request": func(ss *SessionStore[string]) {
+			_, _ = ss.GetFromRequest(nil, httptest.NewRequest(http.MethodGet, "/", nil))
+		},
+		"Clone":  func(ss *SessionStore[string]) { ss.CloneReadOnly().Get("missing") },
+		"SetUid": func(ss *SessionStore[string]) { ss.New("value").SetUid("custom") },
+		"Update": func(ss *SessionStore[string]) {
+			_ = ss.UpdateRequirements(func(r *Requirements[string]) { r.Timeout = time.Hour })
+		},
+		"ReadOnly": func(ss *SessionStore[string]) { ss.SetReadOnly(true); ss.ReadOnly() },
+		"Current":  func(ss *SessionStore[string]) { ss.CurrentRequirements() },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("Expected %s to work on a zero value store, got panic: %v", name, r)
+				}
+			}()
+
+			f(&SessionStore[string]{})
+		}()
+
+		func() {
+			defer func() {
+				if r := recover(); r != ErrNilStore {
+					t.Errorf("Expected %s to panic with ErrNilStore on a nil store, got %v", name, r)
+				}
+			}()
+
+			f(nil)
+		}()
+	}
+}
+
+func TestMustNew(t *testing.T) {
+	if ss := MustNew[string](nil); ss == nil {
+		t.Errorf("Expected store to be created")
+	}
+
+	defer func() {
+		if r, ok := recover().(error); !ok || !errors.Is(r, ErrInvalidRequirements) {
+			t.Errorf("Expected panic with ErrInvalidRequirements, got %v", r)
+		}
+	}()
+
+	MustNew[string](&Requirements[string]{TombstoneTimeout: -1})
+}
+
+func FuzzGetFromRequest(f *testing.F) {
+	ss := initializeSessionStore(0, &Requirements[string]{Keys: [][]byte{bytes.Repeat([]byte("k"), 32)}})
+	s := ss.New("value").(*Session[string])
+	valid := ss.cookieValue(s.Uid())
+
+	f.Add("_ssid=" + valid)
+	f.Add("_ssid=" + valid + "; _ssid=" + valid)
+	f.Add("_ssid=\"" + valid + "\"")
+	f.Add("_ssid=")
+	f.Add(";;=;")
+
+	f.Fuzz(func(t *testing.T, header string) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Cookie", header)
+
+		got, err := ss.GetFromRequest(httptest.NewRecorder(), req)
+		if got == nil {
+			if err == nil {
+				t.Errorf("Expected an error along with nil session for \"%s\"", header)
+			}
+			return
+		}
+
+		cookies := req.CookiesNamed(ss.req().DefaultKey)
+		if err != nil || got != s || len(cookies) != 1 || cookies[0].Value != valid {
+			t.Errorf("Expected session to be found only through its own cookie, got it for \"%s\"", header)
+		}
+	})
+}
+
+func TestNewE(t *testing.T) {
+	if _, err := NewE[string](nil); err != nil {
+		t.Errorf("Expected nil Requirements to be valid, got %s", err)
+	}
+
+	_, err := NewE[string](&Requirements[string]{
+		DefaultKey: "__Host-ssid",
+		Timeout:    -time.Second,
+	})
+
+	if !errors.Is(err, ErrInvalidRequirements) {
+		t.Fatalf("Expected ErrInvalidRequirements, got %v", err)
+	}
+
+	if n := len(strings.Split(err.Error(), "\n")); n != 3 {
+		t.Errorf("Expected 3 problems to be reported, got %d: %s", n, err)
+	}
+}
+
+func TestSessionStore_UpdateRequirements(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+
+	err := ss.UpdateRequirements(func(r *Requirements[string]) {
+		r.DefaultKey = "new_key"
+	})
+	if err != nil {
+		t.Fatalf("Expected the update to succeed, got %s", err)
+	}
+
+	if k := ss.New("value").Key(); k != "new_key" {
+		t.Errorf("Expected new sessions to use key \"new_key\", got \"%s\"", k)
+	}
+
+	err = ss.UpdateRequirements(func(r *Requirements[string]) {
+		r.DefaultKey = "bad key"
+	})
+	if !errors.Is(err, ErrInvalidRequirements) {
+		t.Errorf("Expected ErrInvalidRequirements for an invalid key, got %v", err)
+	}
+
+	if k := ss.CurrentRequirements().DefaultKey; k != "new_key" {
+		t.Errorf("Expected invalid update to be discarded, got key \"%s\"", k)
+	}
+}
+
+func BenchmarkSessionStore_New(b *testing.B) {
+	ss := initializeSessionStore(0, &Requirements[string]{Timeout: time.Hour})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ss.New("value")
+	}
+}
+
+func BenchmarkSessionStore_Get(b *testing.B) {
+	ss := initializeSessionStore(0, &Requirements[string]{Timeout: time.Hour})
+	uids := make([]string, 1000)
+	for i := range uids {
+		uids[i] = ss.New("value").Uid()
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			ss.Get(uids[i%len(uids)])
+			i++
+		}
+	})
+}
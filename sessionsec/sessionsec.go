@@ -0,0 +1,229 @@
+//Package sessionsec is a security conformance suite for session stores. Run it from a test of your own, with the
+//Requirements your application uses, to check that the configuration doesn't open the door to session fixation,
+//replay of sessions after logout, forged cookies or sessions outliving their expiry:
+//
+//	func TestSessions(t *testing.T) {
+//		sessionsec.Run(t, func() *sessions.Requirements[User] { return appRequirements() }, User{ID: 1})
+//	}
+package sessionsec
+
+import (
+	"github.com/emillis/sessions"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//How long sessions live in the expiry race check
+const expiryRaceDeadline = 50 * time.Millisecond
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Run runs every check of the suite as a subtest. Each check gets a fresh store created from the Requirements returned
+//by newRequirements, and value is used as the value of every session created
+func Run[TValue any](t *testing.T, newRequirements func() *sessions.Requirements[TValue], value TValue) {
+	t.Run("Fixation", func(t *testing.T) { Fixation(t, newRequirements, value) })
+	t.Run("ReplayAfterLogout", func(t *testing.T) { ReplayAfterLogout(t, newRequirements, value) })
+	t.Run("Tampering", func(t *testing.T) { Tampering(t, newRequirements, value) })
+	t.Run("ExpiryRace", func(t *testing.T) { ExpiryRace(t, newRequirements, value) })
+}
+
+//Fixation checks that a UID planted by an attacker is never adopted and that the UID a session had before it was
+//regenerated, e.g. on login, no longer leads to it
+func Fixation[TValue any](t *testing.T, newRequirements func() *sessions.Requirements[TValue], value TValue) {
+	ss := newStore(t, newRequirements)
+	name := ss.CurrentRequirements().DefaultKey
+
+	planted := requestWithCookie(name, "attacker-chosen-uid")
+	ss.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sessions.FromContext[TValue](r.Context()) != nil {
+			t.Errorf("Planted cookie must not resolve to a session")
+		}
+	})).ServeHTTP(httptest.NewRecorder(), planted)
+
+	if ss.Exist("attacker-chosen-uid") {
+		t.Errorf("Planted UID must not be adopted by the store")
+	}
+
+	s := newSession(t, ss, value)
+	before := requestFor(t, s)
+
+	rg, ok := s.(sessions.Regenerator)
+	if !ok {
+		t.Fatalf("Sessions must implement sessions.Regenerator to be protected against fixation")
+	}
+	rg.Regenerate()
+
+	if got, err := ss.GetFromRequest(nil, before); got != nil || err == nil {
+		t.Errorf("Cookie issued before the session was regenerated must not lead to it")
+	}
+
+	if got, err := ss.GetFromRequest(nil, requestFor(t, s)); got != s || err != nil {
+		t.Errorf("Cookie issued after the session was regenerated must lead to it, got %v", err)
+	}
+}
+
+//ReplayAfterLogout checks that cookies of a removed session can't bring it back, also after the store was flushed to
+//Requirements.Backend
+func ReplayAfterLogout[TValue any](t *testing.T, newRequirements func() *sessions.Requirements[TValue], value TValue) {
+	ss := newStore(t, newRequirements)
+	s := newSession(t, ss, value)
+	r := requestFor(t, s)
+
+	if ss.CurrentRequirements().Backend != nil {
+		if err := ss.FlushToBackend(); err != nil {
+			t.Fatalf("Failed to flush the session to the backend: %s", err)
+		}
+	}
+
+	ss.Remove(s.Uid())
+
+	if got, err := ss.GetFromRequest(nil, r); got != nil || err == nil {
+		t.Errorf("Cookie of a removed session must not lead to it")
+	}
+
+	if ss.CurrentRequirements().Backend != nil {
+		if err := ss.FlushToBackend(); err != nil {
+			t.Fatalf("Failed to flush the store to the backend: %s", err)
+		}
+	}
+
+	if ss.Get(s.Uid()) != nil {
+		t.Errorf("Removed session must not be loaded back")
+	}
+}
+
+//Tampering checks that altered cookie values don't lead to the session they were derived from or any other
+func Tampering[TValue any](t *testing.T, newRequirements func() *sessions.Requirements[TValue], value TValue) {
+	ss := newStore(t, newRequirements)
+	s := newSession(t, ss, value)
+	name := ss.CurrentRequirements().DefaultKey
+
+	original := requestFor(t, s).Cookies()[0].Value
+
+	tampered := map[string]string{
+		"empty":               "",
+		"truncated":           original[:len(original)-1],
+		"appended":            original + "A",
+		"first char flipped":  flip(original, 0),
+		"last char flipped":   flip(original, len(original)-1),
+		"middle char flipped": flip(original, len(original)/2),
+	}
+
+	for what, v := range tampered {
+		if got, err := ss.GetFromRequest(nil, requestWithCookie(name, v)); got != nil || err == nil {
+			t.Errorf("Cookie with %s value must not lead to a session", what)
+		}
+	}
+}
+
+//ExpiryRace checks that a session is never returned once its deadline has passed, while it's being read concurrently
+//right around the deadline
+func ExpiryRace[TValue any](t *testing.T, newRequirements func() *sessions.Requirements[TValue], value TValue) {
+	ss := newStore(t, newRequirements)
+	s := newSession(t, ss, value)
+	r := requestFor(t, s)
+
+	e, ok := s.(sessions.Expirer)
+	if !ok {
+		t.Skip("Sessions don't implement sessions.Expirer")
+	}
+
+	deadline := time.Now().Add(expiryRaceDeadline)
+	e.ExpireAt(deadline)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for time.Now().Before(deadline.Add(expiryRaceDeadline)) {
+				start := time.Now()
+				got, _ := ss.GetFromRequest(nil, r.Clone(r.Context()))
+
+				if got != nil && !start.Before(deadline) {
+					t.Errorf("Session must not be returned after its deadline")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ss.Exist(s.Uid()) {
+		t.Errorf("Session must be gone once its deadline has passed")
+	}
+}
+
+//Creates the store, failing the test if the Requirements are invalid
+func newStore[TValue any](t *testing.T, newRequirements func() *sessions.Requirements[TValue]) *sessions.SessionStore[TValue] {
+	t.Helper()
+
+	ss, err := sessions.NewE(newRequirements())
+	if err != nil {
+		t.Fatalf("Invalid requirements: %s", err)
+	}
+
+	return ss
+}
+
+//Creates a session, failing the test if it can't be created
+func newSession[TValue any](t *testing.T, ss *sessions.SessionStore[TValue], value TValue) sessions.ISession[TValue] {
+	t.Helper()
+
+	s, err := ss.NewE(value)
+	if err != nil {
+		t.Fatalf("Failed to create a session: %s", err)
+	}
+
+	return s
+}
+
+//Returns a request carrying the cookie the session sets
+func requestFor[TValue any](t *testing.T, s sessions.ISession[TValue]) *http.Request {
+	t.Helper()
+
+	c, ok := s.(sessions.CookieWriter)
+	if !ok {
+		t.Skip("Sessions don't implement sessions.CookieWriter")
+	}
+
+	w := httptest.NewRecorder()
+	c.SetHttpCookie(w, nil)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == "" {
+		t.Fatalf("Expected the session to set exactly one cookie, got %d", len(cookies))
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookies[0])
+
+	return r
+}
+
+//Returns a request carrying a cookie with the name and value
+func requestWithCookie(name, value string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: name, Value: value})
+	return r
+}
+
+//Returns the string with the character at i replaced by one that differs in the high bits of base64, which, unlike
+//the low ones, are never discarded as padding
+func flip(s string, i int) string {
+	b := []byte(s)
+
+	if b[i] >= 'a' {
+		b[i] = 'A'
+	} else {
+		b[i] = 'g'
+	}
+
+	return string(b)
+}
@@ -0,0 +1,23 @@
+package sessionsec
+
+import (
+	"bytes"
+	"github.com/emillis/sessions"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestRun_Defaults(t *testing.T) {
+	Run(t, func() *sessions.Requirements[string] { return nil }, "value")
+}
+
+func TestRun_Keys(t *testing.T) {
+	Run(t, func() *sessions.Requirements[string] {
+		return &sessions.Requirements[string]{
+			Keys:             [][]byte{bytes.Repeat([]byte("k"), 32)},
+			TombstoneTimeout: time.Minute,
+		}
+	}, "value")
+}
@@ -0,0 +1,133 @@
+package sessions
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//StoreSnapshot is a serializable copy of the sessions of a store, e.g. to be saved in production and compared with
+//Diff against one taken while reproducing an incident in staging. Sessions are kept as SessionRecord, keyed by their
+//storage key. Their Uid and Metadata.Parent are left empty, so raw UIDs don't end up in the snapshot unless they are
+//used as storage keys
+type StoreSnapshot[TValue any] struct {
+	//TakenAt is the time the snapshot was taken
+	TakenAt time.Time `json:"taken_at" bson:"taken_at"`
+
+	//Sessions are keyed by storage key
+	Sessions map[string]SessionRecord[TValue] `json:"sessions" bson:"sessions"`
+}
+
+//DiffReport lists the differences between two snapshots, each slice sorted by storage key
+type DiffReport struct {
+	//Added are sessions present only in the second snapshot
+	Added []string `json:"added" bson:"added"`
+
+	//Removed are sessions present only in the first snapshot
+	Removed []string `json:"removed" bson:"removed"`
+
+	//Changed are sessions present in both snapshots, mapped to the names of the fields that differ
+	Changed map[string][]string `json:"changed" bson:"changed"`
+}
+
+//Empty checks whether the snapshots are the same
+func (d *DiffReport) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Snapshot returns a serializable copy of the sessions in the copy
+func (ro *ReadOnlySessionStore[TValue]) Snapshot() StoreSnapshot[TValue] {
+	snap := StoreSnapshot[TValue]{
+		TakenAt:  ro.createdAt,
+		Sessions: make(map[string]SessionRecord[TValue], len(ro.sessions)),
+	}
+
+	for uid, s := range ro.sessions {
+		rec := s.record()
+		rec.Uid, rec.Metadata.Parent = "", ""
+		snap.Sessions[ro.storageKeys[uid]] = rec
+	}
+
+	return snap
+}
+
+//Snapshot returns a serializable copy of the sessions in the store, hibernated ones included, see CloneReadOnly
+func (ss *SessionStore[TValue]) Snapshot() StoreSnapshot[TValue] {
+	ss.ready()
+
+	return ss.CloneReadOnly().Snapshot()
+}
+
+//Returns names of the fields that differ between the two states of the session
+func changedFields[TValue any](a, b SessionRecord[TValue]) []string {
+	var fields []string
+
+	if a.Key != b.Key {
+		fields = append(fields, "Key")
+	}
+	if !reflect.DeepEqual(a.Value, b.Value) {
+		fields = append(fields, "Value")
+	}
+	if a.Metadata.Label != b.Metadata.Label {
+		fields = append(fields, "Label")
+	}
+	if !a.LastModified.Equal(b.LastModified) {
+		fields = append(fields, "LastModified")
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		fields = append(fields, "CreatedAt")
+	}
+	if !a.ExpiresAt.Equal(b.ExpiresAt) {
+		fields = append(fields, "ExpiresAt")
+	}
+	if a.Metadata.Priority != b.Metadata.Priority {
+		fields = append(fields, "Priority")
+	}
+	if !reflect.DeepEqual(a.Metadata.Attrs, b.Metadata.Attrs) {
+		fields = append(fields, "Attrs")
+	}
+	if a.Metadata.Token != b.Metadata.Token || !slices.Equal(a.Metadata.TokenScopes, b.Metadata.TokenScopes) {
+		fields = append(fields, "Token")
+	}
+	if !slices.Equal(a.Metadata.Blobs, b.Metadata.Blobs) {
+		fields = append(fields, "Blobs")
+	}
+	if a.Version != b.Version {
+		fields = append(fields, "Version")
+	}
+
+	return fields
+}
+
+//Diff compares two snapshots and reports which sessions were added, removed or changed from a to b
+func Diff[TValue any](a, b StoreSnapshot[TValue]) DiffReport {
+	report := DiffReport{Changed: make(map[string][]string)}
+
+	for key, sa := range a.Sessions {
+		sb, exist := b.Sessions[key]
+		if !exist {
+			report.Removed = append(report.Removed, key)
+			continue
+		}
+
+		if fields := changedFields(sa, sb); len(fields) > 0 {
+			report.Changed[key] = fields
+		}
+	}
+
+	for key := range b.Sessions {
+		if _, exist := a.Sessions[key]; !exist {
+			report.Added = append(report.Added, key)
+		}
+	}
+
+	sort.Strings(report.Added)
+	sort.Strings(report.Removed)
+
+	return report
+}
@@ -0,0 +1,57 @@
+package sessions
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestDiff(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	kept, changed, removed := ss.New("kept"), ss.New("changed"), ss.New("removed")
+
+	before := ss.Snapshot()
+	clockOf(ss).Advance(time.Millisecond)
+
+	changed.SetValue("new value")
+	ss.Remove(removed.Uid())
+	added := ss.New("added")
+
+	report := Diff(before, ss.Snapshot())
+
+	if len(report.Added) != 1 || report.Added[0] != added.Uid() {
+		t.Errorf("Expected \"%s\" to be reported as added, got %v", added.Uid(), report.Added)
+	}
+
+	if len(report.Removed) != 1 || report.Removed[0] != removed.Uid() {
+		t.Errorf("Expected \"%s\" to be reported as removed, got %v", removed.Uid(), report.Removed)
+	}
+
+	if fields := report.Changed[changed.Uid()]; len(fields) != 2 || fields[0] != "Value" || fields[1] != "LastModified" {
+		t.Errorf("Expected Value and LastModified to be reported as changed, got %v", fields)
+	}
+
+	if _, exist := report.Changed[kept.Uid()]; exist || len(report.Changed) != 1 {
+		t.Errorf("Expected only one session to be reported as changed, got %v", report.Changed)
+	}
+}
+
+func TestSnapshot_Records(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	s := ss.New("value").(*Session[string])
+	theme := NewAttrKey[string]("theme")
+	theme.Set(s, "dark")
+
+	before := ss.Snapshot()
+	rec := before.Sessions[s.StorageKey()]
+	if rec.Metadata.Attrs["theme"] != "dark" || rec.Uid != "" {
+		t.Errorf("Expected the snapshot to hold the record of the session without its UID, got %+v", rec)
+	}
+
+	theme.Set(s, "light")
+	if fields := Diff(before, ss.Snapshot()).Changed[s.StorageKey()]; !slices.Contains(fields, "Attrs") {
+		t.Errorf("Expected the changed attribute to be reported, got %v", fields)
+	}
+}
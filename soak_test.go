@@ -0,0 +1,189 @@
+//go:build sessions_soak
+
+package sessions
+
+import (
+	"context"
+	"flag"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//The soak test is long-running, so it's only built with the sessions_soak tag:
+//
+//	go test -tags sessions_soak -run TestSoak -timeout 1h -soak.sessions 1000000
+var (
+	soakSessions = flag.Int("soak.sessions", 1_000_000, "number of sessions churned by the soak test")
+	soakWorkers  = flag.Int("soak.workers", 4*runtime.GOMAXPROCS(0), "number of goroutines churning sessions")
+	soakTimeout  = flag.Duration("soak.timeout", 50*time.Millisecond, "timeout of sessions that are left to expire")
+	soakHeap     = flag.Uint64("soak.heap", 64<<20, "heap growth in bytes tolerated once the churn has settled")
+)
+
+//Goroutines tolerated on top of the ones running before the churn, e.g. ones of the runtime and the testing package
+const soakGoroutineSlack = 8
+
+//===========[STRUCTS]====================================================================================================
+
+//Backend that accepts every write without keeping it, so the heap is only grown by the store itself
+type soakBackend struct {
+	saves   atomic.Int64
+	removes atomic.Int64
+}
+
+func (b *soakBackend) Load(key string) (string, uint64, error) {
+	return "", 0, ErrNotFound
+}
+
+func (b *soakBackend) Save(s ISession[string], dirtyFields []string, expectedVersion uint64) (uint64, error) {
+	b.saves.Add(1)
+	return expectedVersion + 1, nil
+}
+
+func (b *soakBackend) Remove(key string) error {
+	b.removes.Add(1)
+	return nil
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Returns the heap in use after a full collection
+func soakHeapInUse() uint64 {
+	runtime.GC()
+	runtime.GC()
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	return m.HeapInuse
+}
+
+//Churns a session the way a busy application would: watches, updates, regenerates and removes it or leaves it to
+//expire
+func soakChurn(ss *SessionStore[string], i int, watchers *sync.WaitGroup) {
+	s := ss.New("value").(*Session[string])
+
+	if i%10 == 0 {
+		//Half of the watchers go away on their own, the rest are closed by removal or expiry
+		ctx := context.Background()
+		if i%20 == 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithCancel(ctx)
+			defer cancel()
+		}
+
+		events := s.Watch(ctx)
+
+		watchers.Add(1)
+		go func() {
+			defer watchers.Done()
+			for range events {
+			}
+		}()
+	}
+
+	s.SetValue("updated")
+
+	if i%3 == 0 {
+		s.Regenerate()
+	}
+
+	if i%4 == 0 {
+		ss.Remove(s.Uid())
+	}
+}
+
+func TestSoak(t *testing.T) {
+	backend := &soakBackend{}
+	ss := New[string](&Requirements[string]{
+		Timeout: *soakTimeout,
+		Backend: backend,
+	})
+
+	baseGoroutines := runtime.NumGoroutine()
+	baseHeap := soakHeapInUse()
+
+	var next atomic.Int64
+	var workers, watchers sync.WaitGroup
+
+	done := make(chan struct{})
+	flushed := make(chan struct{})
+
+	//Flushes run concurrently with the churn, as they would on a timer in production
+	go func() {
+		defer close(flushed)
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+				if err := ss.FlushToBackend(); err != nil {
+					t.Errorf("Expected flush to succeed, got %s", err)
+				}
+			}
+		}
+	}()
+
+	start := time.Now()
+
+	for w := 0; w < *soakWorkers; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+
+			for {
+				i := int(next.Add(1))
+				if i > *soakSessions {
+					return
+				}
+
+				soakChurn(ss, i, &watchers)
+			}
+		}()
+	}
+
+	workers.Wait()
+	close(done)
+	<-flushed
+
+	if err := ss.FlushToBackend(); err != nil {
+		t.Errorf("Expected final flush to succeed, got %s", err)
+	}
+
+	t.Logf("Churned %d sessions in %s, %d saves, %d removes", *soakSessions, time.Since(start), backend.saves.Load(), backend.removes.Load())
+
+	//Every session left over expires, which closes the remaining watchers
+	deadline := time.Now().Add(*soakTimeout + time.Minute)
+	for ss._sessions.Count() > 0 || ss._modifiedSessions.Count() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected every session to expire, %d are still stored and %d are still modified", ss._sessions.Count(), ss._modifiedSessions.Count())
+		}
+
+		time.Sleep(*soakTimeout)
+	}
+
+	watchers.Wait()
+
+	if n := ss._blobOwners.Count() + ss._tmpUidStore.Count() + ss._hibernated.Count(); n != 0 {
+		t.Errorf("Expected internal caches to be empty, %d entries are left", n)
+	}
+
+	//Goroutines of expired timers and closed watchers may take a moment to return
+	for deadline = time.Now().Add(10 * time.Second); runtime.NumGoroutine() > baseGoroutines+soakGoroutineSlack; {
+		if time.Now().After(deadline) {
+			t.Errorf("Expected goroutines to return, %d are running, %d were before", runtime.NumGoroutine(), baseGoroutines)
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if heap := soakHeapInUse(); heap > baseHeap+*soakHeap {
+		t.Errorf("Expected heap to settle, %d bytes are in use, %d were before", heap, baseHeap)
+	}
+}
@@ -0,0 +1,96 @@
+package sessions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//How often a comment is sent to keep idle SSE connections open through proxies
+var sseKeepAliveInterval = 15 * time.Second
+
+//===========[STRUCTS]====================================================================================================
+
+//Default payload of SSE events. It deliberately leaves out the UID and the value
+type ssePayload struct {
+	Kind string    `json:"kind"`
+	Time time.Time `json:"time"`
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Writes a single SSE event and flushes it to the client
+func writeSSE(w http.ResponseWriter, f http.Flusher, event string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b); err != nil {
+		return err
+	}
+
+	f.Flush()
+
+	return nil
+}
+
+//SSEHandler returns http.Handler that streams changes of the session found in the request cookie as Server-Sent
+//Events, named after ChangeKind ("value", "regenerated", "removed", "expired"). By default, event data only holds
+//the kind and the time of the change, as the UID is the session secret. The payload function can be supplied to send
+//something else, e.g. parts of the value the frontend needs. Requests without a valid session get 401
+func (ss *SessionStore[TValue]) SSEHandler(payload func(ChangeEvent[TValue]) any) http.Handler {
+	ss.ready()
+
+	if payload == nil {
+		payload = func(ev ChangeEvent[TValue]) any { return ssePayload{Kind: ev.Kind.String(), Time: ev.Time} }
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, ok := ss.tenantSession(r).(Watchable[TValue])
+		if !ok {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		f, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		//Watching before responding, so no change made after the client got the response is missed
+		events := s.Watch(r.Context())
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		f.Flush()
+
+		keepAlive := time.NewTicker(sseKeepAliveInterval)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+
+				if err := writeSSE(w, f, ev.Kind.String(), payload(ev)); err != nil {
+					ss.reportError(fmt.Errorf("sessions: writing SSE event: %w", err))
+					return
+				}
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+				f.Flush()
+			}
+		}
+	})
+}
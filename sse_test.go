@@ -0,0 +1,68 @@
+package sessions
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_SSEHandler(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	s := ss.New("value")
+
+	srv := httptest.NewServer(ss.SSEHandler(nil))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.AddCookie(&http.Cookie{Name: s.Key(), Value: s.Uid()})
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected request to succeed, got %s", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected Content-Type \"text/event-stream\", got \"%s\"", ct)
+	}
+
+	//The handler watches the session before responding
+	ss.Remove(s.Uid())
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if !strings.Contains(string(body), "event: removed") {
+		t.Errorf("Expected the stream to contain removal event, got \"%s\"", body)
+	}
+
+	if strings.Contains(string(body), s.Uid()) {
+		t.Errorf("Expected the stream not to leak the session UID")
+	}
+}
+
+func TestSessionStore_SSEHandlerTenant(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{
+		TenantOf: func(r *http.Request) string { return strings.Split(r.Host, ".")[0] },
+	})
+	s := ss.Tenant("acme").New("value")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for host, want := range map[string]int{"acme.example.com": http.StatusOK, "globex.example.com": http.StatusUnauthorized} {
+		r := httptest.NewRequestWithContext(ctx, http.MethodGet, "http://"+host+"/", nil)
+		r.AddCookie(&http.Cookie{Name: s.Key(), Value: s.Uid()})
+
+		w := httptest.NewRecorder()
+		ss.SSEHandler(nil).ServeHTTP(w, r)
+
+		if w.Code != want {
+			t.Errorf("Expected request to %s to get %d, got %d", host, want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package sessions
+
+import "sync"
+
+//===========[STRUCTS]====================================================================================================
+
+//Stats is a point-in-time report of the SessionStore
+type Stats struct {
+	//Number of sessions currently in the store
+	Active int `json:"active" bson:"active"`
+
+	//Number of sessions whose values were moved to the Backend after being idle
+	Hibernated int `json:"hibernated" bson:"hibernated"`
+
+	//Number of sessions modified since the last flush
+	Modified int `json:"modified" bson:"modified"`
+
+	//Number of generated UIDs that turned out to be taken, either when the session was created or, with
+	//Requirements.LazyUidCheck, when it was first saved to the Backend. Anything above 0 is worth investigating
+	UidCollisions uint64 `json:"uid_collisions" bson:"uid_collisions"`
+
+	//Number of times UID generation gave up after Requirements.MaxUidAttempts, failing to create or regenerate a
+	//session. Anything above 0 usually means that UidChecker is broken
+	UidExhausted uint64 `json:"uid_exhausted" bson:"uid_exhausted"`
+
+	//Number of cookie values and tokens turned away by Requirements.UidFormat before lookup. Sudden growth usually
+	//means someone is guessing UIDs
+	UidRejected uint64 `json:"uid_rejected" bson:"uid_rejected"`
+
+	//Number of expired sessions waiting to be passed to Requirements.OnExpire
+	ExpireQueue int `json:"expire_queue" bson:"expire_queue"`
+
+	//Number of sessions waiting to be created by NewAsync
+	NewQueue int `json:"new_queue" bson:"new_queue"`
+
+	//Estimated memory taken by the values of the sessions. It's only tracked while a quota limits memory
+	Bytes int64 `json:"bytes" bson:"bytes"`
+
+	//Number of sessions refused and evicted because of a quota
+	QuotaRejected uint64 `json:"quota_rejected" bson:"quota_rejected"`
+	QuotaEvicted  uint64 `json:"quota_evicted" bson:"quota_evicted"`
+
+	//Statistics per session label. Sessions created without a label are reported under ""
+	Labels map[string]LabelStats `json:"labels" bson:"labels"`
+}
+
+//LabelStats hold statistics of the sessions tagged with the same label
+type LabelStats struct {
+	//Number of sessions with this label currently in the store
+	Active int `json:"active" bson:"active"`
+
+	//Number of sessions with this label created since the store was initiated
+	Created uint64 `json:"created" bson:"created"`
+
+	//Number of sessions with this label removed since the store was initiated
+	Removed uint64 `json:"removed" bson:"removed"`
+
+	//Estimated memory taken by the values of the sessions with this label, see Stats.Bytes
+	Bytes int64 `json:"bytes" bson:"bytes"`
+}
+
+//Counters collected by the store while it's running
+type storeStats struct {
+	createdByLabel map[string]uint64
+	removedByLabel map[string]uint64
+	uidCollisions  uint64
+	uidExhausted   uint64
+	uidRejected    uint64
+
+	mx sync.Mutex
+}
+
+//Records creation of a session with the label supplied
+func (st *storeStats) created(label string) {
+	st.mx.Lock()
+	if st.createdByLabel == nil {
+		st.createdByLabel = make(map[string]uint64)
+	}
+	st.createdByLabel[label]++
+	st.mx.Unlock()
+}
+
+//Records removal of a session with the label supplied
+func (st *storeStats) removed(label string) {
+	st.mx.Lock()
+	if st.removedByLabel == nil {
+		st.removedByLabel = make(map[string]uint64)
+	}
+	st.removedByLabel[label]++
+	st.mx.Unlock()
+}
+
+//Records a generated UID that was already taken
+func (st *storeStats) collision() {
+	st.mx.Lock()
+	st.uidCollisions++
+	st.mx.Unlock()
+}
+
+//Records UID generation giving up
+func (st *storeStats) exhausted() {
+	st.mx.Lock()
+	st.uidExhausted++
+	st.mx.Unlock()
+}
+
+//Records a UID received from a client that doesn't match Requirements.UidFormat
+func (st *storeStats) rejected() {
+	st.mx.Lock()
+	st.uidRejected++
+	st.mx.Unlock()
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Stats returns current statistics of the store. Sessions removed by timeout are counted in Removed along with the ones
+//removed explicitly
+func (ss *SessionStore[TValue]) Stats() Stats {
+	ss.ready()
+
+	st := Stats{
+		Active:      ss._sessions.Count(),
+		Hibernated:  ss._hibernated.Count(),
+		Modified:    ss._modifiedSessions.Count(),
+		ExpireQueue: ss.expireQueue.depth(),
+		NewQueue:    ss.newQueue.depth(),
+		Labels:      make(map[string]LabelStats),
+	}
+
+	ss._sessions.ForEach(func(_ string, s *Session[TValue]) {
+		l := st.Labels[s.Label()]
+		l.Active++
+		st.Labels[s.Label()] = l
+	})
+
+	ss.stats.mx.Lock()
+	st.UidCollisions = ss.stats.uidCollisions
+	st.UidExhausted = ss.stats.uidExhausted
+	st.UidRejected = ss.stats.uidRejected
+	for label, n := range ss.stats.createdByLabel {
+		l := st.Labels[label]
+		l.Created = n
+		st.Labels[label] = l
+	}
+	for label, n := range ss.stats.removedByLabel {
+		l := st.Labels[label]
+		l.Removed = n
+		st.Labels[label] = l
+	}
+	ss.stats.mx.Unlock()
+
+	ss.quota.mx.Lock()
+	st.Bytes = ss.quota.total.bytes
+	st.QuotaRejected = ss.quota.rejected
+	st.QuotaEvicted = ss.quota.evicted
+	for label, u := range ss.quota.byLabel {
+		if l, exist := st.Labels[label]; exist {
+			l.Bytes = u.bytes
+			st.Labels[label] = l
+		}
+	}
+	ss.quota.mx.Unlock()
+
+	return st
+}
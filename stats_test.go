@@ -0,0 +1,49 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_Stats(t *testing.T) {
+	ss := initializeSessionStore(2, nil)
+
+	mobile := ss.NewLabeled("m1", "mobile")
+	ss.NewLabeled("m2", "mobile")
+	ss.Remove(mobile.Uid())
+
+	st := ss.Stats()
+
+	if st.Active != 3 {
+		t.Errorf("Expected 3 active sessions, got %d", st.Active)
+	}
+
+	if l := st.Labels["mobile"]; l.Active != 1 || l.Created != 2 || l.Removed != 1 {
+		t.Errorf("Expected mobile label stats {Active:1 Created:2 Removed:1}, got %+v", l)
+	}
+
+	if l := st.Labels[""]; l.Active != 2 || l.Created != 2 {
+		t.Errorf("Expected unlabeled stats {Active:2 Created:2}, got %+v", l)
+	}
+}
+
+func TestSessionStore_StatsExpired(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{Timeout: time.Minute})
+
+	s := ss.NewLabeled("value", "mobile")
+	s.SetValue("modified")
+
+	clockOf(ss).Advance(2 * time.Minute)
+
+	st := ss.Stats()
+
+	if st.Active != 0 || st.Modified != 0 {
+		t.Errorf("Expected the expired session to leave both the store and the modified ones, got %+v", st)
+	}
+
+	if l := st.Labels["mobile"]; l.Removed != 1 {
+		t.Errorf("Expected the expired session to be counted as removed, got %+v", l)
+	}
+}
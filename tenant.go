@@ -0,0 +1,195 @@
+package sessions
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+//===========[STRUCTS]====================================================================================================
+
+//Backend of a tenant. Records are stored in the shared Backend under keys prefixed with the tenant name
+type tenantBackend[TValue any] struct {
+	backend Backend[TValue]
+	prefix  string
+}
+
+//Load returns the record of the tenant stored under the key
+func (b *tenantBackend[TValue]) Load(key string) (TValue, uint64, error) {
+	return b.backend.Load(b.prefix + key)
+}
+
+//Save stores the session under the prefixed key. The shared Backend gets the session itself with only its storage key
+//replaced, so whatever else it stores about the session, e.g. its deadline or attributes, is kept
+func (b *tenantBackend[TValue]) Save(s ISession[TValue], dirtyFields []string, expectedVersion uint64) (uint64, error) {
+	return b.backend.Save(storeAs(s, b.prefix+StorageKeyOf(s), s.Value()), dirtyFields, expectedVersion)
+}
+
+//Remove deletes the record of the tenant stored under the key
+func (b *tenantBackend[TValue]) Remove(key string) error {
+	return b.backend.Remove(b.prefix + key)
+}
+
+//SessionExpired passes the prefixed key on to the shared Backend, see ExpiryListener
+func (b *tenantBackend[TValue]) SessionExpired(key string) {
+	if l, ok := b.backend.(ExpiryListener); ok {
+		l.SessionExpired(b.prefix + key)
+	}
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Returns the prefix the keys of the tenant are stored under in shared storage
+func tenantPrefix(name string) string {
+	return "tenant:" + name + "/"
+}
+
+//Tenant returns the partition of the store that belongs to the tenant, creating it on first use. Partitions have
+//their own sessions, UIDs, index, hibernation and stats, so a session of one tenant can never be reached through
+//another. They share Requirements.Backend and Requirements.UidChecker, with keys prefixed by the tenant name. Each
+//partition starts with the Requirements the store has at the time, later changes are made on the partition itself.
+//Read-only mode and draining of the store apply to its partitions as well. Empty name means the store itself.
+//Returns nil if the tenant is refused, in which case the error goes to Requirements.OnError, see TenantE
+func (ss *SessionStore[TValue]) Tenant(name string) *SessionStore[TValue] {
+	ss.ready()
+
+	t, err := ss.TenantE(name)
+	if err != nil {
+		ss.reportError(err)
+		return nil
+	}
+
+	return t
+}
+
+//TenantE does the same as Tenant, but returns ErrInvalidTenant for names containing "/" and ErrTenantLimit once
+//Requirements.MaxTenants partitions exist
+func (ss *SessionStore[TValue]) TenantE(name string) (*SessionStore[TValue], error) {
+	ss.ready()
+
+	if ss.parent != nil {
+		return ss.parent.TenantE(name)
+	}
+
+	if name == "" {
+		return ss, nil
+	}
+
+	if strings.Contains(name, "/") {
+		return nil, ErrInvalidTenant
+	}
+
+	ss.mx.RLock()
+	t, exist := ss.tenants[name]
+	ss.mx.RUnlock()
+
+	if exist {
+		return t, nil
+	}
+
+	ss.mx.Lock()
+	defer ss.mx.Unlock()
+
+	if t, exist = ss.tenants[name]; exist {
+		return t, nil
+	}
+
+	if len(ss.tenants) >= ss.Requirements.MaxTenants {
+		return nil, ErrTenantLimit
+	}
+
+	r := ss.Requirements
+	prefix := tenantPrefix(name)
+
+	if r.Backend != nil {
+		r.Backend = &tenantBackend[TValue]{backend: r.Backend, prefix: prefix}
+	}
+
+	if checker := r.UidChecker; checker != nil {
+		r.UidChecker = UidCheckerFunc(func(ctx context.Context, key string) (bool, error) {
+			return checker.Exists(ctx, prefix+key)
+		})
+	}
+
+	r.TenantOf = nil
+
+	t = &SessionStore[TValue]{}
+	t.clock = ss.clock
+	t.setup(makeRequirementsReasonable(&r))
+	t.parent = ss
+	t.tenant = name
+
+	if ss.tenants == nil {
+		ss.tenants = make(map[string]*SessionStore[TValue])
+	}
+	ss.tenants[name] = t
+
+	return t, nil
+}
+
+//Tenants returns sorted names of the tenants that have a partition
+func (ss *SessionStore[TValue]) Tenants() []string {
+	ss.ready()
+
+	if ss.parent != nil {
+		return ss.parent.Tenants()
+	}
+
+	ss.mx.RLock()
+	defer ss.mx.RUnlock()
+
+	names := make([]string, 0, len(ss.tenants))
+	for name := range ss.tenants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+//TenantName returns the name of the tenant this partition belongs to, or empty string for the store itself
+func (ss *SessionStore[TValue]) TenantName() string {
+	ss.ready()
+
+	return ss.tenant
+}
+
+//TenantFor returns the partition the request belongs to according to Requirements.TenantOf, or the store itself if
+//it's not set. Returns nil if the tenant is refused, in which case the error goes to Requirements.OnError, see TenantE
+func (ss *SessionStore[TValue]) TenantFor(r *http.Request) *SessionStore[TValue] {
+	ss.ready()
+
+	t, err := ss.TenantForE(r)
+	if err != nil {
+		ss.reportError(err)
+		return nil
+	}
+
+	return t
+}
+
+//TenantForE does the same as TenantFor, but returns the error the tenant was refused with
+func (ss *SessionStore[TValue]) TenantForE(r *http.Request) (*SessionStore[TValue], error) {
+	ss.ready()
+
+	tenantOf := ss.req().TenantOf
+	if tenantOf == nil || r == nil {
+		return ss, nil
+	}
+
+	name := ""
+	ss.protectReport("TenantOf", func() { name = tenantOf(r) })
+
+	return ss.TenantE(name)
+}
+
+//Returns the session the request cookie points to in the partition of the tenant, see GetFromCookie
+func (ss *SessionStore[TValue]) tenantSession(r *http.Request) ISession[TValue] {
+	t := ss.TenantFor(r)
+	if t == nil {
+		return nil
+	}
+
+	return t.GetFromCookie(r)
+}
@@ -0,0 +1,115 @@
+package sessions
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_Tenant(t *testing.T) {
+	backend := newTestBackend()
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Backend:  backend,
+		TenantOf: func(r *http.Request) string { return strings.Split(r.Host, ".")[0] },
+	})
+
+	acme, globex := ss.Tenant("acme"), ss.Tenant("globex")
+	if ss.Tenant("acme") != acme || acme.TenantName() != "acme" {
+		t.Fatalf("Expected the same partition to be returned for the same tenant")
+	}
+
+	s := acme.New("value")
+	if globex.Get(s.Uid()) != nil || ss.Get(s.Uid()) != nil {
+		t.Errorf("Expected the session to be reachable through its own tenant only")
+	}
+
+	if err := acme.FlushToBackend(); err != nil {
+		t.Fatal(err)
+	}
+	if _, exist := backend.records[tenantPrefix("acme")+s.Uid()]; !exist {
+		t.Errorf("Expected the session to be stored under the prefix of the tenant")
+	}
+
+	for host, want := range map[string]ISession[string]{"acme.example.com": s, "globex.example.com": nil} {
+		r := httptest.NewRequest(http.MethodGet, "http://"+host+"/", nil)
+		r.AddCookie(&http.Cookie{Name: s.Key(), Value: s.Uid()})
+
+		if got, _ := ss.GetFromRequest(nil, r); got != want {
+			t.Errorf("Expected request to %s to resolve to %v, got %v", host, want, got)
+		}
+	}
+
+	if st := globex.Stats(); st.Active != 0 || acme.Stats().Active != 1 {
+		t.Errorf("Expected stats to be kept per tenant")
+	}
+
+	if names := ss.Tenants(); len(names) != 2 || names[0] != "acme" || names[1] != "globex" {
+		t.Errorf("Expected both tenants to be listed, got %v", names)
+	}
+}
+
+func TestSessionStore_TenantE(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{MaxTenants: 1})
+
+	if _, err := ss.TenantE("acme/admin"); !errors.Is(err, ErrInvalidTenant) {
+		t.Errorf("Expected a name with \"/\" to be refused, got %v", err)
+	}
+
+	if _, err := ss.TenantE("acme"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ss.TenantE("acme"); err != nil {
+		t.Errorf("Expected an existing tenant to be returned past the limit, got %s", err)
+	}
+	if _, err := ss.TenantE("globex"); !errors.Is(err, ErrTenantLimit) {
+		t.Errorf("Expected tenants past MaxTenants to be refused, got %v", err)
+	}
+	if ss.Tenant("globex") != nil {
+		t.Errorf("Expected Tenant to return nil for a refused tenant")
+	}
+}
+
+func TestSessionStore_TenantFollowsParent(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	acme := ss.Tenant("acme")
+
+	ss.SetReadOnly(true)
+	if _, err := acme.NewE("value"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected the tenant to be read-only along with its parent, got %v", err)
+	}
+	ss.SetReadOnly(false)
+
+	ss.Drain()
+	if _, err := acme.NewE("value"); !errors.Is(err, ErrDraining) {
+		t.Errorf("Expected the tenant to be draining along with its parent, got %v", err)
+	}
+}
+
+func TestTenantBackend_Save(t *testing.T) {
+	backend := &recordBackend[string]{records: map[string]SessionRecord[string]{}}
+	ss := initializeSessionStore(0, &Requirements[string]{Backend: backend})
+
+	acme := ss.Tenant("acme")
+	s := acme.New("value")
+	s.(Attributer).SetAttr("role", "admin")
+	deadline := acme.now().Add(time.Hour)
+	s.(Expirer).ExpireAt(deadline)
+
+	if err := acme.FlushToBackend(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, exist := backend.records[tenantPrefix("acme")+s.Uid()]
+	if !exist {
+		t.Fatalf("Expected the session to be stored under the prefix of the tenant")
+	}
+
+	if rec.Uid != s.Uid() || rec.Value != "value" || rec.Metadata.Attrs["role"] != "admin" || !rec.ExpiresAt.Equal(deadline) {
+		t.Errorf("Expected the shared backend to get the session itself, got %+v", rec)
+	}
+}
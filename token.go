@@ -0,0 +1,121 @@
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//TokenLabel is the label API token sessions are tagged with, so they are reported apart from the rest in Stats
+const TokenLabel = "api-token"
+
+var (
+	//ErrInvalidToken is returned by ValidateToken when the token is malformed, unknown or not an API token
+	ErrInvalidToken = errors.New("sessions: invalid token")
+
+	//ErrInsufficientScope is returned by ValidateToken when the token lacks a scope required
+	ErrInsufficientScope = errors.New("sessions: insufficient token scope")
+)
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//NewToken creates a long-lived API token session with the value, e.g. the subject the token was issued to, and the
+//scopes it grants. Unlike cookie sessions, it doesn't time out when idle, but expires after ttl, which must be positive.
+//Returns the opaque token to hand over to the client. Tokens are regular sessions tagged with TokenLabel, so they are
+//stored, indexed and persisted the same way, but they are never accepted from cookies
+func (ss *SessionStore[TValue]) NewToken(data TValue, scopes []string, ttl time.Duration) (string, error) {
+	ss.ready()
+
+	if ttl <= 0 {
+		return "", fmt.Errorf("sessions: token ttl must be positive, got %s", ttl)
+	}
+
+	created, err := ss.newValidated(data, TokenLabel)
+	if err != nil {
+		return "", err
+	}
+
+	s := created.(*Session[TValue])
+
+	s.mx.Lock()
+	s.session.token = true
+	s.session.tokenScopes = slices.Clone(scopes)
+	s.mx.Unlock()
+
+	s.ExpireAt(ss.now().Add(ttl))
+
+	return ss.cookieValue(s.Uid()), nil
+}
+
+//ValidateToken returns the session of the API token, provided it grants every scope listed
+func (ss *SessionStore[TValue]) ValidateToken(token string, scopes ...string) (ISession[TValue], error) {
+	ss.ready()
+
+	uid, _, ok := ss.parseCookieValue(token)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	s, ok := ss.Get(uid).(*Session[TValue])
+	if !ok || !s.IsToken() {
+		return nil, ErrInvalidToken
+	}
+
+	granted := s.TokenScopes()
+	for _, scope := range scopes {
+		if !slices.Contains(granted, scope) {
+			return nil, ErrInsufficientScope
+		}
+	}
+
+	return s, nil
+}
+
+//Tokens returns API token sessions whose fields extracted by Requirements.Index match the subject, e.g.
+//{"user": "42"}, oldest first. Hibernated tokens are included, so RevokeTokens reaches idle ones as well
+func (ss *SessionStore[TValue]) Tokens(subject map[string]string) []ISession[TValue] {
+	ss.ready()
+
+	var tokens []ISession[TValue]
+
+	for _, s := range ss.Search(Query{Fields: subject, Label: TokenLabel}) {
+		if s.(*Session[TValue]).IsToken() {
+			tokens = append(tokens, s)
+		}
+	}
+
+	return tokens
+}
+
+//RevokeTokens removes every API token of the subject, see Tokens. Returns the number of tokens revoked
+func (ss *SessionStore[TValue]) RevokeTokens(subject map[string]string) (int, error) {
+	ss.ready()
+
+	if err := ss.writable(); err != nil {
+		return 0, err
+	}
+
+	tokens := ss.Tokens(subject)
+	for _, s := range tokens {
+		ss.remove(s.Uid())
+	}
+
+	return len(tokens), nil
+}
+
+//IsToken checks whether this session is an API token created by NewToken
+func (s *Session[TValue]) IsToken() bool {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return s.session.token
+}
+
+//TokenScopes returns the scopes granted to the API token
+func (s *Session[TValue]) TokenScopes() []string {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	return slices.Clone(s.session.tokenScopes)
+}
@@ -0,0 +1,132 @@
+package sessions
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSessionStore_NewToken(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Timeout: 50 * time.Millisecond,
+		Index:   func(v string) map[string]string { return map[string]string{"user": v} },
+	})
+
+	token, err := ss.NewToken("alice", []string{"read"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss.New("alice")
+
+	if _, err = ss.ValidateToken(token, "read"); err != nil {
+		t.Errorf("Expected the token to be valid for its scope, got %v", err)
+	}
+
+	if _, err = ss.ValidateToken(token, "read", "write"); err != ErrInsufficientScope {
+		t.Errorf("Expected a missing scope to fail with ErrInsufficientScope, got %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: ss.CurrentRequirements().DefaultKey, Value: token})
+	if s, _ := ss.GetFromRequest(nil, r); s != nil {
+		t.Errorf("Expected the token not to be accepted as a cookie")
+	}
+
+	clockOf(ss).Advance(80 * time.Millisecond)
+	if _, err = ss.ValidateToken(token); err != nil {
+		t.Errorf("Expected the token not to time out when idle, got %v", err)
+	}
+
+	if tokens := ss.Tokens(map[string]string{"user": "alice"}); len(tokens) != 1 {
+		t.Errorf("Expected one token of the subject to be listed, got %d", len(tokens))
+	}
+
+	if n, err := ss.RevokeTokens(map[string]string{"user": "alice"}); n != 1 || err != nil {
+		t.Errorf("Expected one token to be revoked, got %d, %v", n, err)
+	}
+
+	if _, err = ss.ValidateToken(token); err != ErrInvalidToken {
+		t.Errorf("Expected a revoked token to fail with ErrInvalidToken, got %v", err)
+	}
+}
+
+func FuzzValidateToken(f *testing.F) {
+	ss := initializeSessionStore(0, &Requirements[string]{Keys: [][]byte{bytes.Repeat([]byte("k"), 32)}})
+	token, err := ss.NewToken("bot", []string{"read"}, time.Hour)
+	if err != nil {
+		f.Fatalf("Expected token to be issued, got %s", err)
+	}
+	cookie := ss.New("value").(*Session[string])
+
+	f.Add(token, "read")
+	f.Add(token, "write")
+	f.Add(ss.cookieValue(cookie.Uid()), "")
+	f.Add(token+"=", "")
+	f.Add("", "")
+
+	f.Fuzz(func(t *testing.T, value, scope string) {
+		s, err := ss.ValidateToken(value, scope)
+		if s == nil {
+			if err != ErrInvalidToken && err != ErrInsufficientScope {
+				t.Errorf("Expected ErrInvalidToken or ErrInsufficientScope, got %v", err)
+			}
+			return
+		}
+
+		if value != token || scope != "read" {
+			t.Errorf("Expected token to be accepted only as issued and with its scope, got \"%s\" with \"%s\"", value, scope)
+		}
+	})
+}
+
+func TestSessionStore_RevokeHibernatedTokens(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Backend:        newTestBackend(),
+		HibernateAfter: time.Hour,
+		Index:          func(v string) map[string]string { return map[string]string{"user": v} },
+	})
+
+	token, err := ss.NewToken("alice", []string{"read"}, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clockOf(ss).Advance(2 * time.Hour)
+	if st := ss.Stats(); st.Hibernated != 1 {
+		t.Fatalf("Expected the idle token to be hibernated, got %+v", st)
+	}
+
+	if n, err := ss.RevokeTokens(map[string]string{"user": "alice"}); n != 1 || err != nil {
+		t.Errorf("Expected the hibernated token to be revoked, got %d, %v", n, err)
+	}
+
+	if _, err := ss.ValidateToken(token); err != ErrInvalidToken {
+		t.Errorf("Expected the revoked token to be rejected, got %v", err)
+	}
+}
+
+func TestSessionStore_TokenRecord(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+
+	token, err := ss.NewToken("alice", []string{"read"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := ss.ValidateToken(token)
+	rec := ss.ToRecord(s)
+	ss.Remove(s.Uid())
+	ss._tombstones.Remove(StorageKeyOf(s))
+
+	if _, err := ss.FromRecord(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ss.ValidateToken(token, "read"); err != nil {
+		t.Errorf("Expected the token to come back from its record with its scopes, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package sessions
+
+import "time"
+
+//===========[INTERFACES]====================================================================================================
+
+//TTLStrategy decides when sessions time out. The deadline is computed again whenever the session changes, so it can
+//depend on both times supplied. Absolute deadlines set by Session.ExpireAt take precedence over it
+type TTLStrategy interface {
+	//Deadline returns the time the session with the creation and last modification times supplied times out at.
+	//Zero time means it doesn't time out
+	Deadline(createdAt, lastModified time.Time) time.Time
+}
+
+//===========[STRUCTS]====================================================================================================
+
+//FixedTTL times sessions out this long after they were created, no matter how active they are. 0 means never
+type FixedTTL time.Duration
+
+//Deadline returns the creation time plus the duration
+func (d FixedTTL) Deadline(createdAt, _ time.Time) time.Time {
+	if d <= 0 {
+		return time.Time{}
+	}
+
+	return createdAt.Add(time.Duration(d))
+}
+
+//SlidingTTL times sessions out after being idle for this long. It's the default, with Requirements.Timeout as the
+//duration. 0 means never
+type SlidingTTL time.Duration
+
+//Deadline returns the last modification time plus the duration
+func (d SlidingTTL) Deadline(_, lastModified time.Time) time.Time {
+	if d <= 0 {
+		return time.Time{}
+	}
+
+	return lastModified.Add(time.Duration(d))
+}
+
+//AbsoluteIdleTTL times sessions out after being idle for Idle, but no later than Absolute after they were created,
+//as commonly required for authenticated sessions. Either of them can be 0 to disable it
+type AbsoluteIdleTTL struct {
+	Absolute time.Duration `json:"absolute" bson:"absolute"`
+	Idle     time.Duration `json:"idle" bson:"idle"`
+}
+
+//Deadline returns the earlier of the two deadlines
+func (t AbsoluteIdleTTL) Deadline(createdAt, lastModified time.Time) time.Time {
+	absolute := FixedTTL(t.Absolute).Deadline(createdAt, lastModified)
+	idle := SlidingTTL(t.Idle).Deadline(createdAt, lastModified)
+
+	if absolute.IsZero() || (!idle.IsZero() && idle.Before(absolute)) {
+		return idle
+	}
+
+	return absolute
+}
+
+//TTLFunc allows using a plain function as TTLStrategy, e.g. to time sessions out at midnight local time:
+//
+//	TTLFunc(func(createdAt, _ time.Time) time.Time {
+//		y, m, d := createdAt.In(loc).Date()
+//		return time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+//	})
+type TTLFunc func(createdAt, lastModified time.Time) time.Time
+
+//Deadline calls the function itself
+func (f TTLFunc) Deadline(createdAt, lastModified time.Time) time.Time {
+	return f(createdAt, lastModified)
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Returns Requirements.TTL, or SlidingTTL of Requirements.Timeout if it's not set
+func (ss *SessionStore[TValue]) ttl() TTLStrategy {
+	r := ss.req()
+	if r.TTL != nil {
+		return r.TTL
+	}
+
+	return SlidingTTL(r.Timeout)
+}
+
+//Returns when the session with the times supplied times out. A panicking TTL strategy is reported and SlidingTTL of
+//Requirements.Timeout is used instead
+func (ss *SessionStore[TValue]) deadline(createdAt, lastModified time.Time) time.Time {
+	var deadline time.Time
+
+	err := ss.protect("TTL", func() error {
+		deadline = ss.ttl().Deadline(createdAt, lastModified)
+		return nil
+	})
+	if err != nil {
+		ss.reportError(err)
+		return SlidingTTL(ss.req().Timeout).Deadline(createdAt, lastModified)
+	}
+
+	return deadline
+}
+
+//Returns how long the session with the times supplied has left before it times out. false is returned if it doesn't
+//time out
+func (ss *SessionStore[TValue]) timeLeft(createdAt, lastModified time.Time) (time.Duration, bool) {
+	deadline := ss.deadline(createdAt, lastModified)
+	if deadline.IsZero() {
+		return 0, false
+	}
+
+	//0 means no timeout to the caches, so sessions past their deadline get the shortest one instead
+	return max(deadline.Sub(ss.now()), time.Nanosecond), true
+}
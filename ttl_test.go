@@ -0,0 +1,38 @@
+package sessions
+
+import (
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestRequirements_TTL(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{
+		Timeout: time.Hour,
+		TTL:     AbsoluteIdleTTL{Absolute: 100 * time.Millisecond, Idle: time.Hour},
+	})
+	s := ss.New("value")
+
+	clk := clockOf(ss)
+	clk.Advance(60 * time.Millisecond)
+	s.UpdateLastModified()
+
+	if ss.Get(s.Uid()) != s {
+		t.Fatalf("Expected the session to live until the absolute deadline")
+	}
+
+	clk.Advance(40 * time.Millisecond)
+	if ss.Get(s.Uid()) != nil {
+		t.Errorf("Expected activity not to extend the session past the absolute deadline")
+	}
+
+	now := time.Now()
+	if d := (AbsoluteIdleTTL{Absolute: time.Hour, Idle: time.Minute}).Deadline(now, now); !d.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected the idle deadline to be the earlier one, got %s", d)
+	}
+
+	if d := SlidingTTL(0).Deadline(now, now); !d.IsZero() {
+		t.Errorf("Expected zero SlidingTTL to never time out, got %s", d)
+	}
+}
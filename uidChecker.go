@@ -0,0 +1,75 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+const (
+	//UidCheckAssumeUnique treats the UID as unique when the check fails. UIDs are long and random, so this is the
+	//default, but failures should be watched through Requirements.OnError
+	UidCheckAssumeUnique UidCheckFallback = iota
+
+	//UidCheckAssumeExists treats the UID as taken when the check fails, so another one is generated
+	UidCheckAssumeExists
+)
+
+//===========[INTERFACES]====================================================================================================
+
+//UidChecker checks for existence of the UID outside the SessionStore, e.g. in a database. It receives the storage
+//key of the UID: Requirements.HashUid of the UID if it's set, otherwise the HMAC of the UID with the primary one of
+//Requirements.Keys if they are set, otherwise the UID itself
+type UidChecker interface {
+	//Exists reports whether the key is already in use. The error is returned when it can't be determined
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+//===========[STRUCTS]====================================================================================================
+
+//UidCheckFallback defines what happens with the UID when UidChecker fails
+type UidCheckFallback int
+
+//UidCheckerFunc allows using a plain function as UidChecker
+type UidCheckerFunc func(ctx context.Context, uid string) (bool, error)
+
+//Exists calls the function itself
+func (f UidCheckerFunc) Exists(ctx context.Context, uid string) (bool, error) {
+	return f(ctx, uid)
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Reports the error to Requirements.OnError, if set
+func (ss *SessionStore[TValue]) reportError(err error) {
+	if onError := ss.req().OnError; onError != nil {
+		//A panicking OnError has nowhere to be reported to, so the panic is dropped
+		_ = ss.protect("OnError", func() error { onError(err); return nil })
+	}
+}
+
+//Checks the uid with Requirements.UidChecker, applying timeout and fallback policy
+func (ss *SessionStore[TValue]) checkUid(key string) bool {
+	r := ss.req()
+	ctx := context.Background()
+
+	if r.UidCheckTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.UidCheckTimeout)
+		defer cancel()
+	}
+
+	var exist bool
+	err := ss.protect("UidChecker", func() (err error) {
+		exist, err = r.UidChecker.Exists(ctx, key)
+		return err
+	})
+	if err == nil {
+		return exist
+	}
+
+	ss.reportError(fmt.Errorf("sessions: checking uid existence: %w", err))
+
+	return r.UidCheckFallback == UidCheckAssumeExists
+}
@@ -0,0 +1,86 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestRequirements_LazyUidCheck(t *testing.T) {
+	checks := 0
+	backend := &collidingBackend{newTestBackend(), 1}
+	ss := initializeSessionStore(0, &Requirements[string]{
+		LazyUidCheck: true,
+		Backend:      backend,
+		UidChecker:   UidCheckerFunc(func(context.Context, string) (bool, error) { checks++; return false, nil }),
+	})
+
+	s := ss.New("value")
+	uid := s.Uid()
+
+	if checks != 0 {
+		t.Errorf("Expected UidChecker not to be called, got %d calls", checks)
+	}
+
+	if err := ss.FlushToBackend(); err != nil {
+		t.Fatalf("Expected flush to resolve the collision, got %s", err)
+	}
+
+	if s.Uid() == uid || ss.Get(s.Uid()) != s || ss.Get(uid) != nil {
+		t.Errorf("Expected the colliding session to be moved under a new UID")
+	}
+
+	if _, exist := backend.records[StorageKeyOf(s)]; !exist {
+		t.Errorf("Expected the session to be saved under the new UID")
+	}
+
+	if n := ss.Stats().UidCollisions; n != 1 {
+		t.Errorf("Expected 1 collision to be reported, got %d", n)
+	}
+}
+
+func TestRequirements_MaxUidAttempts(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{
+		MaxUidAttempts: 3,
+		UidChecker:     UidCheckerFunc(func(context.Context, string) (bool, error) { return true, nil }),
+	})
+
+	if _, err := ss.NewE("value"); err != ErrUidExhausted {
+		t.Errorf("Expected NewE to give up with ErrUidExhausted, got %v", err)
+	}
+
+	if st := ss.Stats(); st.UidCollisions != 3 || st.UidExhausted != 1 {
+		t.Errorf("Expected 3 collisions and 1 exhaustion, got %d and %d", st.UidCollisions, st.UidExhausted)
+	}
+}
+
+func TestRequirements_UidChecker(t *testing.T) {
+	var reported []error
+	calls := 0
+
+	ss := initializeSessionStore(0, &Requirements[string]{
+		UidChecker: UidCheckerFunc(func(ctx context.Context, uid string) (bool, error) {
+			calls++
+			if calls == 1 {
+				return false, errors.New("database is down")
+			}
+			return false, nil
+		}),
+		UidCheckFallback: UidCheckAssumeExists,
+		OnError:          func(err error) { reported = append(reported, err) },
+	})
+
+	if ss.New("value") == nil {
+		t.Fatalf("Expected a session to be created after the failed check")
+	}
+
+	if calls != 2 {
+		t.Errorf("Expected a failed check to be retried with another UID, got %d calls", calls)
+	}
+
+	if len(reported) != 1 {
+		t.Errorf("Expected the failure to be reported once, got %d", len(reported))
+	}
+}
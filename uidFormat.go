@@ -0,0 +1,118 @@
+package sessions
+
+import (
+	"crypto/rand"
+	"hash/crc32"
+	"strings"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+//Characters UIDs generated by the store are made of
+const uidAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_="
+
+//Length of UIDs generated by the store
+const uidLength = 99
+
+//===========[STRUCTS]====================================================================================================
+
+//UidFormat describes UIDs the store accepts from cookies and tokens. Values that don't match are rejected before they
+//are verified, hashed or looked up, so garbage and guessing are turned away cheaply. They are counted in
+//Stats.UidRejected. The zero value accepts any UID. Only narrow it down if every UID in use matches, e.g. when UIDs
+//aren't set by SetUid or brought in by Import
+type UidFormat struct {
+	//MinLength and MaxLength bound the length of the UID in bytes. 0 means no bound
+	MinLength int `json:"min_length" bson:"min_length"`
+	MaxLength int `json:"max_length" bson:"max_length"`
+
+	//Alphabet lists the ASCII characters UIDs may be made of. Empty means any
+	Alphabet string `json:"alphabet" bson:"alphabet"`
+
+	//Checksum makes the last character of generated UIDs a checksum of the rest, so made up and mistyped UIDs are
+	//rejected without a lookup. It's drawn from Alphabet, or from the characters of generated UIDs if it's empty.
+	//Sessions created before it was turned on stop being found through cookies
+	Checksum bool `json:"checksum" bson:"checksum"`
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//GeneratedUidFormat returns the format of UIDs generated by the store, with the checksum turned on. It's meant for
+//Requirements.UidFormat of stores whose every UID is generated
+func GeneratedUidFormat() UidFormat {
+	return UidFormat{MinLength: uidLength, MaxLength: uidLength, Alphabet: uidAlphabet, Checksum: true}
+}
+
+//Returns the checksum character of the rest of the UID
+func (f UidFormat) checksum(rest string) byte {
+	alphabet := f.Alphabet
+	if alphabet == "" {
+		alphabet = uidAlphabet
+	}
+
+	return alphabet[crc32.ChecksumIEEE([]byte(rest))%uint32(len(alphabet))]
+}
+
+//Match reports whether the UID is in the format
+func (f UidFormat) Match(uid string) bool {
+	if len(uid) < f.MinLength || (f.MaxLength > 0 && len(uid) > f.MaxLength) {
+		return false
+	}
+
+	if f.Alphabet != "" {
+		for i := 0; i < len(uid); i++ {
+			if strings.IndexByte(f.Alphabet, uid[i]) < 0 {
+				return false
+			}
+		}
+	}
+
+	if f.Checksum && (uid == "" || uid[len(uid)-1] != f.checksum(uid[:len(uid)-1])) {
+		return false
+	}
+
+	return true
+}
+
+//Returns a new UID made of characters of uidAlphabet drawn from crypto/rand. UIDs are the session secret and
+//Requirements.LazyUidCheck counts on them never colliding, so they must not be predictable
+func randomUid() (string, error) {
+	//Bytes at or above the largest multiple of the alphabet size are skipped, so every character is equally likely
+	limit := byte(256 / len(uidAlphabet) * len(uidAlphabet))
+
+	uid := make([]byte, 0, uidLength)
+	buf := make([]byte, uidLength+uidLength/2)
+
+	for len(uid) < uidLength {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+
+		for _, b := range buf {
+			if b < limit && len(uid) < uidLength {
+				uid = append(uid, uidAlphabet[int(b)%len(uidAlphabet)])
+			}
+		}
+	}
+
+	return string(uid), nil
+}
+
+//Returns the generated UID with its last character replaced by the checksum, if the format has one
+func (f UidFormat) seal(uid string) string {
+	if !f.Checksum || uid == "" {
+		return uid
+	}
+
+	return uid[:len(uid)-1] + string(f.checksum(uid[:len(uid)-1]))
+}
+
+//Checks the UID received from a client against Requirements.UidFormat, counting the ones rejected
+func (ss *SessionStore[TValue]) acceptUid(r *Requirements[TValue], uid string) bool {
+	if r.UidFormat.Match(uid) {
+		return true
+	}
+
+	ss.stats.rejected()
+
+	return false
+}
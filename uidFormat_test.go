@@ -0,0 +1,92 @@
+package sessions
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestRequirements_UidFormat(t *testing.T) {
+	for _, keys := range [][][]byte{nil, {bytes.Repeat([]byte("k"), 32)}} {
+		ss := initializeSessionStore(0, &Requirements[string]{UidFormat: GeneratedUidFormat(), Keys: keys})
+		s := ss.New("value").(*Session[string])
+
+		if !ss.req().UidFormat.Match(s.Uid()) {
+			t.Fatalf("Expected generated UID \"%s\" to match the format", s.Uid())
+		}
+
+		if got := ss.GetFromCookie(&testHttpRequest{cookie: &http.Cookie{Name: ss.req().DefaultKey, Value: ss.cookieValue(s.Uid())}}); got != s {
+			t.Errorf("Expected session to be found through a well-formed cookie")
+		}
+
+		uid := s.Uid()
+		bad := uid[:len(uid)-1] + string(uidAlphabet[(strings.IndexByte(uidAlphabet, uid[len(uid)-1])+1)%len(uidAlphabet)])
+
+		for _, value := range []string{"short", uid + "x", strings.Repeat("!", uidLength), bad} {
+			if _, err := ss.getByCookieValue(ss.cookieValue(value), false); err != ErrMalformedCookie {
+				t.Errorf("Expected \"%s\" to be rejected as malformed, got %v", value, err)
+			}
+		}
+
+		if n := ss.Stats().UidRejected; n != 4 {
+			t.Errorf("Expected 4 rejected UIDs, got %d", n)
+		}
+
+		token, err := ss.NewToken("bot", nil, time.Hour)
+		if err != nil {
+			t.Fatalf("Expected token to be issued, got %s", err)
+		}
+
+		if _, err = ss.ValidateToken(token); err != nil {
+			t.Errorf("Expected token with a generated UID to be valid, got %s", err)
+		}
+
+		if _, err = ss.ValidateToken(ss.cookieValue(bad)); err != ErrInvalidToken {
+			t.Errorf("Expected ErrInvalidToken, got %v", err)
+		}
+	}
+
+	if ss := initializeSessionStore(0, nil); !ss.req().UidFormat.Match("anything goes") {
+		t.Errorf("Expected zero value format to accept any UID")
+	}
+
+	for _, f := range []UidFormat{{MinLength: -1}, {MaxLength: -1}, {MinLength: 10, MaxLength: 5}} {
+		if err := (&Requirements[string]{UidFormat: f}).Validate(); !errors.Is(err, ErrInvalidRequirements) {
+			t.Errorf("Expected %+v to be invalid, got %v", f, err)
+		}
+	}
+}
+
+func TestRandomUid(t *testing.T) {
+	seen := make(map[string]bool)
+	counts := make(map[byte]int)
+
+	for i := 0; i < 1000; i++ {
+		uid, err := randomUid()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(uid) != uidLength || !(UidFormat{Alphabet: uidAlphabet}).Match(uid) {
+			t.Fatalf("Expected %d characters of the UID alphabet, got \"%s\"", uidLength, uid)
+		}
+
+		if seen[uid] {
+			t.Fatalf("Expected UIDs not to repeat, got \"%s\" twice", uid)
+		}
+		seen[uid] = true
+
+		for j := 0; j < len(uid); j++ {
+			counts[uid[j]]++
+		}
+	}
+
+	if len(counts) != len(uidAlphabet) {
+		t.Errorf("Expected every character of the alphabet to be used, got %d of %d", len(counts), len(uidAlphabet))
+	}
+}
@@ -0,0 +1,35 @@
+package sessions
+
+//===========[STRUCTS]====================================================================================================
+
+//ValidationError is returned when Requirements.ValidateValue rejects a value
+type ValidationError struct {
+	//Err is the error returned by Requirements.ValidateValue
+	Err error
+}
+
+//Error describes why the value was rejected
+func (e *ValidationError) Error() string {
+	return "sessions: invalid value: " + e.Err.Error()
+}
+
+//Unwrap returns the error returned by Requirements.ValidateValue
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Checks the value with Requirements.ValidateValue
+func (ss *SessionStore[TValue]) validateValue(v TValue) error {
+	validate := ss.req().ValidateValue
+	if validate == nil {
+		return nil
+	}
+
+	if err := ss.protect("ValidateValue", func() error { return validate(v) }); err != nil {
+		return &ValidationError{Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package sessions
+
+import (
+	"errors"
+	"testing"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestRequirements_ValidateValue(t *testing.T) {
+	errEmpty := errors.New("empty value")
+	var reported error
+	ss := initializeSessionStore(0, &Requirements[string]{
+		ValidateValue: func(v string) error {
+			if v == "" {
+				return errEmpty
+			}
+			return nil
+		},
+		OnError: func(err error) { reported = err },
+	})
+
+	var ve *ValidationError
+	if _, err := ss.NewE(""); !errors.As(err, &ve) || !errors.Is(err, errEmpty) {
+		t.Errorf("Expected ValidationError wrapping the validator error, got %v", err)
+	}
+
+	if ss.New("") != nil || !errors.Is(reported, errEmpty) {
+		t.Errorf("Expected New to reject the value and report the error")
+	}
+
+	s := ss.New("value").(*Session[string])
+
+	if err := s.SetValueE(""); !errors.Is(err, errEmpty) || s.Value() != "value" {
+		t.Errorf("Expected SetValueE to reject the value, got %v", err)
+	}
+
+	if err := s.UpdateE(func(v *string) { *v = "" }); !errors.Is(err, errEmpty) || s.Value() != "value" {
+		t.Errorf("Expected UpdateE to reject the value and leave it unchanged, got %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package sessions
+
+import (
+	"context"
+	"time"
+)
+
+//===========[CACHE/STATIC]=============================================================================================
+
+const (
+	//ChangeValue is emitted when the value of the session changes
+	ChangeValue ChangeKind = iota
+
+	//ChangeRegenerated is emitted when the session gets a new UID
+	ChangeRegenerated
+
+	//ChangeRemoved is emitted when the session is removed from the store
+	ChangeRemoved
+
+	//ChangeExpired is emitted when the session times out or reaches its deadline
+	ChangeExpired
+
+	//ChangeCreated is recorded in the journal when the session is created. Watchers never receive it
+	ChangeCreated
+)
+
+//Number of events buffered for a watcher that isn't reading
+const watchBuffer = 16
+
+//===========[STRUCTS]====================================================================================================
+
+//ChangeKind defines what happened to the session
+type ChangeKind int
+
+//String returns the name of the change, e.g. to be used as an event name
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeValue:
+		return "value"
+	case ChangeRegenerated:
+		return "regenerated"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeExpired:
+		return "expired"
+	case ChangeCreated:
+		return "created"
+	default:
+		return "unknown"
+	}
+}
+
+//ChangeEvent describes a single change of a watched session
+type ChangeEvent[TValue any] struct {
+	Kind  ChangeKind `json:"kind" bson:"kind"`
+	Uid   string     `json:"uid" bson:"uid"`
+	Value TValue     `json:"value" bson:"value"`
+	Time  time.Time  `json:"time" bson:"time"`
+}
+
+//Terminal checks whether the session is gone and no more events will follow
+func (e ChangeEvent[TValue]) Terminal() bool {
+	return e.Kind == ChangeRemoved || e.Kind == ChangeExpired
+}
+
+//Single subscription to changes of a session
+type watcher[TValue any] struct {
+	events chan ChangeEvent[TValue]
+}
+
+//===========[FUNCTIONALITY]====================================================================================================
+
+//Sends the event to every watcher of the session and records it in the journal. Watchers that fall behind miss the
+//event
+func (s *Session[TValue]) notify(kind ChangeKind) {
+	if s.store != nil {
+		s.store.journalRecord(kind, s)
+	}
+
+	s.mx.RLock()
+	if len(s.session.watchers) == 0 {
+		s.mx.RUnlock()
+		return
+	}
+
+	ev := ChangeEvent[TValue]{Kind: kind, Uid: s.session.Uid, Value: s.session.Value, Time: s.now()}
+	watchers := make([]*watcher[TValue], 0, len(s.session.watchers))
+	for w := range s.session.watchers {
+		watchers = append(watchers, w)
+	}
+	s.mx.RUnlock()
+
+	for _, w := range watchers {
+		select {
+		case w.events <- ev:
+		default:
+		}
+	}
+}
+
+//Checks whether the session is still present in its store
+func (s *Session[TValue]) stored(key string) bool {
+	if s.store == nil {
+		return false
+	}
+
+	for _, k := range []string{s.StorageKey(), key} {
+		if s.store._sessions.GetValue(k) == s || s.store._hibernated.GetValue(k) == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+//Watch returns a channel of changes of this session: value updates, regeneration, removal and expiry. The channel is
+//closed when the context is done or after the session is removed or expires. Watchers that don't keep up miss
+//intermediate events
+func (s *Session[TValue]) Watch(ctx context.Context) <-chan ChangeEvent[TValue] {
+	out := make(chan ChangeEvent[TValue], watchBuffer)
+	w := &watcher[TValue]{events: make(chan ChangeEvent[TValue], watchBuffer)}
+
+	s.mx.Lock()
+	if s.session.watchers == nil {
+		s.session.watchers = make(map[*watcher[TValue]]struct{})
+	}
+	s.session.watchers[w] = struct{}{}
+	s.mx.Unlock()
+
+	//Expiry is announced by the store as it happens, so only a session that is already gone has to be checked here
+	if !s.stored(s.StorageKey()) {
+		s.mx.RLock()
+		w.events <- ChangeEvent[TValue]{Kind: ChangeExpired, Uid: s.session.Uid, Value: s.session.Value, Time: s.now()}
+		s.mx.RUnlock()
+	}
+
+	go func() {
+		defer close(out)
+		defer func() {
+			s.mx.Lock()
+			delete(s.session.watchers, w)
+			s.mx.Unlock()
+		}()
+
+		for {
+			var ev ChangeEvent[TValue]
+
+			select {
+			case <-ctx.Done():
+				return
+			case ev = <-w.events:
+			}
+
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+
+			if ev.Terminal() {
+				return
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,59 @@
+package sessions
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+//===========[TESTING]====================================================================================================
+
+func TestSession_Watch(t *testing.T) {
+	ss := initializeSessionStore(0, nil)
+	s := ss.New("value").(*Session[string])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := s.Watch(ctx)
+
+	s.SetValue("changed")
+	ss.Remove(s.Uid())
+
+	var kinds []ChangeKind
+	for ev := range events {
+		kinds = append(kinds, ev.Kind)
+	}
+
+	if len(kinds) != 2 || kinds[0] != ChangeValue || kinds[1] != ChangeRemoved {
+		t.Errorf("Expected events [value removed], got %v", kinds)
+	}
+}
+
+func TestSession_WatchExpiry(t *testing.T) {
+	ss := initializeSessionStore(0, &Requirements[string]{Timeout: time.Minute})
+	s := ss.New("value").(*Session[string])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := s.Watch(ctx)
+	clockOf(ss).Advance(2 * time.Minute)
+
+	select {
+	case ev := <-events:
+		if ev.Kind != ChangeExpired {
+			t.Errorf("Expected the expiry to be announced, got %+v", ev)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatalf("Expected watchers to be told about the expiry as it happens")
+	}
+
+	if _, open := <-events; open {
+		t.Errorf("Expected the channel to be closed after the expiry")
+	}
+
+	if ev, open := <-s.Watch(ctx); !open || ev.Kind != ChangeExpired {
+		t.Errorf("Expected a watch of an expired session to end right away, got %+v", ev)
+	}
+}